@@ -3,6 +3,7 @@ package packtrack
 import (
 	"context"
 	"errors"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -12,21 +13,73 @@ import (
 type AsyncOption func(*AsyncConfig)
 
 type AsyncConfig struct {
-	BatchSize     int
-	FlushInterval time.Duration
-	QueueCapacity int
+	BatchSize      int
+	FlushInterval  time.Duration
+	QueueCapacity  int
+	OverflowPolicy OverflowPolicy
+	// Workers is the number of goroutines taking events off the shared
+	// queue, each building and sending its own batches.
+	Workers int
+	// MaxInFlight caps concurrent ingest requests across workers and Flush.
+	// Zero means one per worker.
+	MaxInFlight int
+}
+
+// OverflowPolicy decides what Enqueue does when the queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowReject returns ErrQueueFull. It is the default.
+	OverflowReject OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued event to make room,
+	// reported as DropReasonOverflowOldest.
+	OverflowDropOldest
+	// OverflowDropNewest discards the event being enqueued and returns nil,
+	// reported as DropReasonOverflowNewest.
+	OverflowDropNewest
+	// OverflowBlock waits for space, for the client to close, or, with
+	// EnqueueContext, for the context to be done.
+	OverflowBlock
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowReject:
+		return "reject"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowDropNewest:
+		return "drop_newest"
+	case OverflowBlock:
+		return "block"
+	}
+	return "OverflowPolicy(" + strconv.Itoa(int(p)) + ")"
 }
 
 func defaultAsyncConfig() AsyncConfig {
-	return AsyncConfig{BatchSize: 100, FlushInterval: time.Second, QueueCapacity: 10000}
+	return AsyncConfig{BatchSize: 100, FlushInterval: time.Second, QueueCapacity: 10000, Workers: 1}
 }
 
 func WithBatchSize(n int) AsyncOption { return func(a *AsyncConfig) { a.BatchSize = n } }
 func WithFlushInterval(d time.Duration) AsyncOption {
 	return func(a *AsyncConfig) { a.FlushInterval = d }
 }
+
+// WithQueueCapacity sets how many events the queue holds. It must be at
+// least 1. Defaults to 10000.
 func WithQueueCapacity(n int) AsyncOption { return func(a *AsyncConfig) { a.QueueCapacity = n } }
 
+// WithWorkers sets the number of concurrent batching workers. Defaults to 1.
+func WithWorkers(n int) AsyncOption { return func(a *AsyncConfig) { a.Workers = n } }
+
+// WithMaxInFlight caps the number of ingest requests sent concurrently.
+func WithMaxInFlight(n int) AsyncOption { return func(a *AsyncConfig) { a.MaxInFlight = n } }
+
+// WithOverflowPolicy sets what Enqueue does when the queue is full.
+func WithOverflowPolicy(p OverflowPolicy) AsyncOption {
+	return func(a *AsyncConfig) { a.OverflowPolicy = p }
+}
+
 // AsyncClient wraps a sync Client with background batching.
 type AsyncClient interface {
 	Enqueue(e Event) error
@@ -34,13 +87,63 @@ type AsyncClient interface {
 	Close(ctx context.Context) error
 }
 
+// ContextEnqueuer is an optional interface for AsyncClients that take a
+// context when enqueueing. The AsyncClient returned by NewAsyncClient
+// implements it; use the EnqueueContext function to call it on any
+// AsyncClient.
+type ContextEnqueuer interface {
+	// EnqueueContext is like Enqueue, but fills empty Workflow fields from
+	// the workflow carried by ctx, and stops waiting for space under
+	// OverflowBlock when ctx is done, returning ctx.Err().
+	EnqueueContext(ctx context.Context, e Event) error
+}
+
+// EnqueueContext enqueues e on a through ContextEnqueuer when a implements
+// it. Otherwise it fills empty Workflow fields of e from ctx and calls
+// a.Enqueue.
+func EnqueueContext(ctx context.Context, a AsyncClient, e Event) error {
+	if ce, ok := a.(ContextEnqueuer); ok {
+		return ce.EnqueueContext(ctx, e)
+	}
+	fillWorkflow(ctx, &e)
+	return a.Enqueue(e)
+}
+
+// preparer is implemented by clients whose event pipeline (defaults,
+// filtering, processors, validation) AsyncClient runs at Enqueue. Batches are then sent
+// via ingestPrepared without re-running it.
+type preparer interface {
+	prepareEvent(ctx context.Context, e *Event) (keep bool, err error)
+	ingestPrepared(ctx context.Context, events []Event) (IngestResponse, error)
+}
+
+// Errors returned by AsyncClient.Enqueue.
+var (
+	ErrQueueFull = errors.New("queue full")
+	ErrClosed    = errors.New("async client closed")
+)
+
 type asyncClient struct {
-	base   Client
-	cfg    AsyncConfig
-	q      chan Event
-	wg     sync.WaitGroup
-	mu     sync.Mutex
-	closed bool
+	base  Client
+	prep  preparer      // nil when base is not a preparer
+	hooks *MetricsHooks // the base client's hooks, if any
+	cfg   AsyncConfig
+	q     chan Event
+	wg    sync.WaitGroup
+	// mu is held for reading while sending on q and for writing to close
+	// it. done is closed first so that blocked senders give up the lock.
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+	inflight  chan struct{}       // semaphore bounding concurrent ingests
+	flushes   []chan flushRequest // one per worker
+}
+
+// flushRequest asks a worker to send its partial batch.
+type flushRequest struct {
+	ctx  context.Context
+	done chan error // buffered
 }
 
 // NewAsyncClient creates an AsyncClient on top of an existing Client.
@@ -54,96 +157,238 @@ func NewAsyncClient(base Client, opts ...AsyncOption) (AsyncClient, error) {
 			opt(&cfg)
 		}
 	}
+	if cfg.QueueCapacity < 1 {
+		return nil, errors.New("async queue capacity must be at least 1")
+	}
+	if cfg.Workers < 1 {
+		return nil, errors.New("async workers must be at least 1")
+	}
+	if cfg.MaxInFlight < 0 {
+		return nil, errors.New("async max in-flight must not be negative")
+	}
+	if cfg.MaxInFlight == 0 {
+		cfg.MaxInFlight = cfg.Workers
+	}
 	ac := &asyncClient{
-		base: base,
-		cfg:  cfg,
-		q:    make(chan Event, cfg.QueueCapacity),
+		base:     base,
+		cfg:      cfg,
+		q:        make(chan Event, cfg.QueueCapacity),
+		done:     make(chan struct{}),
+		inflight: make(chan struct{}, cfg.MaxInFlight),
+		flushes:  make([]chan flushRequest, cfg.Workers),
+	}
+	ac.prep, _ = base.(preparer)
+	if c, ok := base.(*client); ok {
+		ac.hooks = c.cfg.MetricsHooks
+	}
+	ac.wg.Add(cfg.Workers)
+	for i := range ac.flushes {
+		ac.flushes[i] = make(chan flushRequest)
+		go ac.worker(ac.flushes[i])
 	}
-	ac.wg.Add(1)
-	go ac.worker()
 	return ac, nil
 }
 
 func (a *asyncClient) Enqueue(e Event) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	return a.EnqueueContext(context.Background(), e)
+}
+
+func (a *asyncClient) EnqueueContext(ctx context.Context, e Event) error {
+	if a.prep != nil {
+		keep, err := a.prep.prepareEvent(ctx, &e)
+		if err != nil {
+			a.hooks.enqueueRejected(RejectReasonInvalid, 1)
+			return err
+		}
+		if !keep {
+			return nil
+		}
+	} else {
+		fillWorkflow(ctx, &e)
+	}
+	a.mu.RLock()
+	dropped, err := a.push(ctx, e)
+	a.mu.RUnlock()
+	// Hooks run after unlocking.
+	switch {
+	case errors.Is(err, ErrClosed):
+		a.hooks.enqueueRejected(RejectReasonClosed, 1)
+	case errors.Is(err, ErrQueueFull):
+		a.hooks.enqueueRejected(RejectReasonQueueFull, 1)
+	case err != nil:
+		a.hooks.enqueueRejected(RejectReasonCanceled, 1)
+	case a.cfg.OverflowPolicy == OverflowDropNewest && dropped > 0:
+		a.hooks.dropped(DropReasonOverflowNewest, dropped)
+	default:
+		if dropped > 0 {
+			a.hooks.dropped(DropReasonOverflowOldest, dropped)
+		}
+		a.hooks.enqueued(1)
+		a.hooks.queueDepth(len(a.q))
+	}
+	return err
+}
+
+// push adds e to the queue according to the overflow policy and returns the
+// number of events it discarded. The caller holds a.mu for reading.
+func (a *asyncClient) push(ctx context.Context, e Event) (dropped int, err error) {
 	if a.closed {
-		return errors.New("async client closed")
+		return 0, ErrClosed
 	}
 	select {
 	case a.q <- e:
-		return nil
+		return 0, nil
 	default:
-		return errors.New("queue full")
 	}
+	switch a.cfg.OverflowPolicy {
+	case OverflowDropNewest:
+		return 1, nil
+	case OverflowDropOldest:
+		for {
+			select {
+			case a.q <- e:
+				return dropped, nil
+			default:
+			}
+			select {
+			case <-a.q:
+				dropped++
+			default: // a worker made room
+			}
+		}
+	case OverflowBlock:
+		select {
+		case a.q <- e:
+			return 0, nil
+		case <-a.done:
+			return 0, ErrClosed
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	return 0, ErrQueueFull
 }
 
+// Flush sends the events queued so far, then has each worker send its
+// partial batch.
 func (a *asyncClient) Flush(ctx context.Context) error {
-	// Signal flush via special timer path by draining current queue into a batch
-	// Implemented by spinning a temporary batch on demand.
+	var errs []error
 	var batch []Event
-	for {
+drain:
+	for n := len(a.q); n > 0; n-- {
+		var e Event
 		select {
-		case e := <-a.q:
-			batch = append(batch, e)
-			if len(batch) >= a.cfg.BatchSize {
-				_, err := a.base.IngestBatch(ctx, batch)
-				return err
+		case ev, ok := <-a.q:
+			if !ok {
+				break drain
+			}
+			e = ev
+		default: // workers took the rest
+			break drain
+		}
+		if batch = append(batch, e); len(batch) >= a.cfg.BatchSize {
+			if _, err := a.ingest(ctx, batch); err != nil {
+				errs = append(errs, err)
 			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		if _, err := a.ingest(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	reqs := make([]flushRequest, 0, len(a.flushes))
+	for _, ch := range a.flushes {
+		req := flushRequest{ctx: ctx, done: make(chan error, 1)}
+		select {
+		case ch <- req:
+			reqs = append(reqs, req)
+		case <-a.done: // closing; workers flush on their own
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
-			if len(batch) > 0 {
-				_, err := a.base.IngestBatch(ctx, batch)
-				return err
+		}
+	}
+	for _, req := range reqs {
+		select {
+		case err := <-req.done:
+			if err != nil {
+				errs = append(errs, err)
 			}
-			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
+	return errors.Join(errs...)
 }
 
 func (a *asyncClient) Close(ctx context.Context) error {
+	a.closeOnce.Do(func() { close(a.done) })
 	a.mu.Lock()
-	a.closed = true
-	close(a.q)
+	if !a.closed {
+		a.closed = true
+		close(a.q)
+	}
 	a.mu.Unlock()
 	a.wg.Wait()
 	return a.base.Close(ctx)
 }
 
-func (a *asyncClient) worker() {
+// ingest sends a batch, skipping the base client's pipeline when it already
+// ran at Enqueue, and reports the flush and the remaining queue depth. It
+// waits for an in-flight slot first.
+func (a *asyncClient) ingest(ctx context.Context, batch []Event) (resp IngestResponse, err error) {
+	a.hooks.queueDepth(len(a.q))
+	select {
+	case a.inflight <- struct{}{}:
+		defer func() { <-a.inflight }()
+	case <-ctx.Done():
+		a.hooks.ingestFailure(len(batch))
+		return IngestResponse{}, ctx.Err()
+	}
+	start := time.Now()
+	if a.prep != nil {
+		resp, err = a.prep.ingestPrepared(ctx, batch)
+	} else {
+		resp, err = a.base.IngestBatch(ctx, batch)
+	}
+	a.hooks.flush(time.Since(start), len(batch))
+	return resp, err
+}
+
+// worker batches events from the shared queue until it is closed, sending
+// its final partial batch before returning.
+func (a *asyncClient) worker(flushes <-chan flushRequest) {
 	defer a.wg.Done()
 	var batch []Event
-	flush := func() {
+	send := func(ctx context.Context) error {
 		if len(batch) == 0 {
-			return
+			return nil
 		}
+		_, err := a.ingest(ctx, batch)
+		batch = batch[:0]
+		return err
+	}
+	flush := func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		_, _ = a.base.IngestBatch(ctx, batch)
+		_ = send(ctx)
 		cancel()
-		batch = batch[:0]
 	}
+	// tick stays nil, and so never fires, when FlushInterval is disabled.
 	var t *time.Timer
+	var tick <-chan time.Time
+	if a.cfg.FlushInterval > 0 {
+		t = time.NewTimer(a.cfg.FlushInterval)
+		defer t.Stop()
+		tick = t.C
+	}
 	resetTimer := func() {
-		if a.cfg.FlushInterval <= 0 {
-			return
-		}
-		if t == nil {
-			t = time.NewTimer(a.cfg.FlushInterval)
-		} else {
-			if !t.Stop() {
-				select {
-				case <-t.C:
-				default:
-				}
-			}
-			t.Reset(a.cfg.FlushInterval)
+		if t != nil {
+			t.Reset(a.cfg.FlushInterval) // drops any stale tick (Go 1.23 timers)
 		}
 	}
 	for {
-		if a.cfg.FlushInterval > 0 && t == nil {
-			t = time.NewTimer(a.cfg.FlushInterval)
-		}
 		select {
 		case e, ok := <-a.q:
 			if !ok {
@@ -155,14 +400,12 @@ func (a *asyncClient) worker() {
 				flush()
 				resetTimer()
 			}
-		case <-func() <-chan time.Time {
-			if t != nil {
-				return t.C
-			}
-			return make(chan time.Time)
-		}():
+		case <-tick:
 			flush()
 			resetTimer()
+		case req := <-flushes:
+			req.done <- send(req.ctx)
+			resetTimer()
 		}
 	}
 }
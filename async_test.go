@@ -2,8 +2,13 @@ package packtrack
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -61,3 +66,311 @@ func TestAsync_Flush(t *testing.T) {
 		t.Fatalf("expected 1 call after flush")
 	}
 }
+
+func TestAsync_MetricsHooks(t *testing.T) {
+	var mu sync.Mutex
+	var depths, flushed, sent []int
+	rejected := map[string]int{}
+	var ac AsyncClient
+	hooks := &MetricsHooks{
+		OnQueueDepth: func(n int) { mu.Lock(); depths = append(depths, n); mu.Unlock() },
+		OnIngestSuccess: func(n int) {
+			mu.Lock()
+			sent = append(sent, n)
+			mu.Unlock()
+		},
+		OnEnqueueRejected: func(reason string, n int) {
+			mu.Lock()
+			rejected[reason] += n
+			mu.Unlock()
+		},
+		OnFlush: func(_ time.Duration, n int) {
+			mu.Lock()
+			flushed = append(flushed, n)
+			reenter := len(flushed) == 2
+			mu.Unlock()
+			if reenter {
+				// Re-entering the client must not deadlock.
+				_ = ac.Enqueue(eventWithMessage("r"))
+			}
+		},
+	}
+	// m1 is in flight and the only worker is stalled, so the queue fills.
+	ac, release, got := stalledAsync(t, []Option{WithMetricsHooks(hooks), WithStrictValidation()})
+	mu.Lock()
+	depths = nil
+	mu.Unlock()
+	_ = ac.Enqueue(eventWithMessage("m2"))
+	_ = ac.Enqueue(eventWithMessage("m3"))
+	if err := ac.Enqueue(eventWithMessage("m4")); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Enqueue on a full queue = %v", err)
+	}
+	if err := ac.Enqueue(Event{}); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	// The worker sends m1, m2, m3, and r, one per batch. r is enqueued from
+	// the hook for m2, while the worker waits, so every depth is exact.
+	release()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		mu.Lock()
+		n := len(flushed)
+		mu.Unlock()
+		if n == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("flushed %d batches", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := ac.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	_ = ac.Close(context.Background())
+	if err := ac.Enqueue(newTestEvent()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Enqueue after Close = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []int{1, 1, 1, 1}; !slices.Equal(flushed, want) || !slices.Equal(sent, want) {
+		t.Errorf("flushed = %v, sent = %v, want %v", flushed, sent, want)
+	}
+	if want := []string{"m1", "m2", "m3", "r"}; !slices.Equal(got(), want) {
+		t.Errorf("server got %v, want %v", got(), want)
+	}
+	// Enqueue m2 and m3; send m2, enqueue r, send m3, send r.
+	if want := []int{1, 2, 1, 2, 1, 0}; !slices.Equal(depths, want) {
+		t.Errorf("depths = %v, want %v", depths, want)
+	}
+	if rejected[RejectReasonQueueFull] != 1 || rejected[RejectReasonInvalid] != 1 || rejected[RejectReasonClosed] != 1 {
+		t.Errorf("rejected = %v", rejected)
+	}
+}
+
+// stalledAsync returns an AsyncClient with a queue of two whose only worker is
+// stuck sending a first event "m1" until release is called. got returns the
+// messages the server received.
+func stalledAsync(t *testing.T, copts []Option, opts ...AsyncOption) (ac AsyncClient, release func(), got func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var msgs []string
+	arrived := make(chan struct{}, 1)
+	gate := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []Event
+		_ = json.NewDecoder(r.Body).Decode(&batch)
+		mu.Lock()
+		for _, e := range batch {
+			msgs = append(msgs, e.Message)
+		}
+		mu.Unlock()
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-gate
+		w.WriteHeader(200)
+	}))
+	t.Cleanup(ts.Close)
+	c, _ := NewClient(append([]Option{WithBaseURL(ts.URL), WithAPIKey("k")}, copts...)...)
+	ac, _ = NewAsyncClient(c, append([]AsyncOption{WithBatchSize(1), WithFlushInterval(0), WithQueueCapacity(2)}, opts...)...)
+	if err := ac.Enqueue(eventWithMessage("m1")); err != nil {
+		t.Fatal(err)
+	}
+	<-arrived
+	var once sync.Once
+	release = func() { once.Do(func() { close(gate) }) }
+	t.Cleanup(release)
+	return ac, release, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(msgs)
+	}
+}
+
+func eventWithMessage(msg string) Event {
+	e := newTestEvent()
+	e.Message = msg
+	return e
+}
+
+func TestAsync_OverflowPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy  OverflowPolicy
+		wantErr error
+		want    []string
+		dropped map[string]int
+	}{
+		{OverflowReject, ErrQueueFull, []string{"m1", "m2", "m3"}, map[string]int{}},
+		{OverflowDropOldest, nil, []string{"m1", "m3", "m4"}, map[string]int{DropReasonOverflowOldest: 1}},
+		{OverflowDropNewest, nil, []string{"m1", "m2", "m3"}, map[string]int{DropReasonOverflowNewest: 1}},
+	} {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			var mu sync.Mutex
+			dropped := map[string]int{}
+			hooks := &MetricsHooks{OnDropped: func(reason string, n int) {
+				mu.Lock()
+				dropped[reason] += n
+				mu.Unlock()
+			}}
+			ac, release, got := stalledAsync(t, []Option{WithMetricsHooks(hooks)}, WithOverflowPolicy(tc.policy))
+			for _, m := range []string{"m2", "m3"} {
+				if err := ac.Enqueue(eventWithMessage(m)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ac.Enqueue(eventWithMessage("m4")); !errors.Is(err, tc.wantErr) {
+				t.Errorf("overflowing enqueue = %v, want %v", err, tc.wantErr)
+			}
+			release()
+			_ = ac.Close(context.Background())
+			if g := got(); !slices.Equal(g, tc.want) {
+				t.Errorf("sent %v, want %v", g, tc.want)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !maps.Equal(dropped, tc.dropped) {
+				t.Errorf("dropped = %v, want %v", dropped, tc.dropped)
+			}
+		})
+	}
+}
+
+func TestAsync_OverflowBlock(t *testing.T) {
+	ac, release, got := stalledAsync(t, nil, WithOverflowPolicy(OverflowBlock))
+	_ = ac.Enqueue(eventWithMessage("m2"))
+	_ = ac.Enqueue(eventWithMessage("m3"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := EnqueueContext(ctx, ac, eventWithMessage("late")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("EnqueueContext = %v, want deadline exceeded", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- ac.Enqueue(eventWithMessage("m4")) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Enqueue returned %v while the queue was full", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	_ = ac.Close(context.Background())
+	if g := got(); !slices.Equal(g, []string{"m1", "m2", "m3", "m4"}) {
+		t.Errorf("sent %v", g)
+	}
+}
+
+func TestAsync_OverflowBlockUnblocksOnClose(t *testing.T) {
+	ac, release, _ := stalledAsync(t, nil, WithOverflowPolicy(OverflowBlock))
+	_ = ac.Enqueue(eventWithMessage("m2"))
+	_ = ac.Enqueue(eventWithMessage("m3"))
+	done := make(chan error, 1)
+	go func() { done <- ac.Enqueue(eventWithMessage("m4")) }()
+	time.Sleep(10 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() { _ = ac.Close(context.Background()); close(closed) }()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("blocked Enqueue = %v, want ErrClosed", err)
+	}
+	release()
+	<-closed
+}
+
+func TestAsync_EnqueueContextWorkflow(t *testing.T) {
+	var got []Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"))
+	ac, _ := NewAsyncClient(c, WithFlushInterval(0))
+	e := newTestEvent()
+	e.Workflow = Workflow{}
+	ctx := ContextWithWorkflow(context.Background(), Workflow{ID: "wf-ctx", RunID: "r1"})
+	if err := EnqueueContext(ctx, ac, e); err != nil {
+		t.Fatal(err)
+	}
+	_ = ac.Close(context.Background())
+	if len(got) != 1 || got[0].Workflow != (Workflow{ID: "wf-ctx", RunID: "r1"}) {
+		t.Errorf("sent %+v", got)
+	}
+
+	// AsyncClients without EnqueueContext get the workflow filled too.
+	var plain plainAsync
+	if err := EnqueueContext(ctx, &plain, e); err != nil || plain.last.Workflow.ID != "wf-ctx" {
+		t.Errorf("fallback: %v %+v", err, plain.last.Workflow)
+	}
+}
+
+type plainAsync struct{ last Event }
+
+func (p *plainAsync) Enqueue(e Event) error       { p.last = e; return nil }
+func (p *plainAsync) Flush(context.Context) error { return nil }
+func (p *plainAsync) Close(context.Context) error { return nil }
+
+func TestAsync_WorkersAndMaxInFlight(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		opts            []AsyncOption
+		wantConcurrency int32
+	}{
+		{"workers", []AsyncOption{WithWorkers(4)}, 4},
+		{"max-in-flight", []AsyncOption{WithWorkers(4), WithMaxInFlight(2)}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var cur, peak, events atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var batch []Event
+				_ = json.NewDecoder(r.Body).Decode(&batch)
+				n := cur.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(30 * time.Millisecond)
+				cur.Add(-1)
+				events.Add(int32(len(batch)))
+				w.WriteHeader(200)
+			}))
+			defer ts.Close()
+			c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"))
+			ac, err := NewAsyncClient(c, append([]AsyncOption{WithBatchSize(1), WithFlushInterval(0)}, tc.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 12 {
+				if err := ac.Enqueue(newTestEvent()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ac.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if events.Load() != 12 {
+				t.Errorf("Close returned with %d of 12 events sent", events.Load())
+			}
+			if p := peak.Load(); p != tc.wantConcurrency {
+				t.Errorf("peak concurrency = %d, want %d", p, tc.wantConcurrency)
+			}
+		})
+	}
+
+	c, _ := NewClient(WithAPIKey("k"))
+	if _, err := NewAsyncClient(c, WithWorkers(0)); err == nil {
+		t.Error("accepted zero workers")
+	}
+}
+
+func TestNewAsyncClient_RejectsZeroCapacity(t *testing.T) {
+	c, _ := NewClient(WithBaseURL("http://example"), WithAPIKey("k"))
+	for _, n := range []int{0, -1} {
+		if _, err := NewAsyncClient(c, WithQueueCapacity(n), WithOverflowPolicy(OverflowDropOldest)); err == nil {
+			t.Errorf("queue capacity %d accepted", n)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package packtrack
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// EventBuilder assembles an Event fluently. A builder may be reused: Build
+// returns an independent copy each time.
+//
+//	ev := packtrack.NewEvent().
+//		Source("billing", "prod").
+//		Actor("agent", "a-1").
+//		Workflow("wf-1").
+//		ForRun("run-42", "charge").
+//		WithMeta("order_id", 123).
+//		Info("charged card").
+//		Build()
+type EventBuilder struct {
+	e Event
+}
+
+// NewEvent starts a new EventBuilder.
+func NewEvent() *EventBuilder { return &EventBuilder{} }
+
+// At sets the event timestamp. Build uses time.Now().UTC() when unset.
+func (b *EventBuilder) At(t time.Time) *EventBuilder {
+	b.e.Timestamp = t
+	return b
+}
+
+// Source sets the emitting system and environment.
+func (b *EventBuilder) Source(system, env string) *EventBuilder {
+	b.e.Source = Source{System: system, Env: env}
+	return b
+}
+
+// Actor sets the actor type and ID.
+func (b *EventBuilder) Actor(typ, id string) *EventBuilder {
+	b.e.Actor.Type = typ
+	b.e.Actor.ID = id
+	return b
+}
+
+// ActorDisplayName sets the actor display name.
+func (b *EventBuilder) ActorDisplayName(name string) *EventBuilder {
+	b.e.Actor.DisplayName = name
+	return b
+}
+
+// Workflow sets the workflow ID.
+func (b *EventBuilder) Workflow(id string) *EventBuilder {
+	b.e.Workflow.ID = id
+	return b
+}
+
+// WorkflowName sets the workflow name.
+func (b *EventBuilder) WorkflowName(name string) *EventBuilder {
+	b.e.Workflow.Name = name
+	return b
+}
+
+// ForRun sets the workflow run and step IDs.
+func (b *EventBuilder) ForRun(runID, stepID string) *EventBuilder {
+	b.e.Workflow.RunID = runID
+	b.e.Workflow.StepID = stepID
+	return b
+}
+
+// Status sets the event status.
+func (b *EventBuilder) Status(s Status) *EventBuilder {
+	b.e.Status = s
+	return b
+}
+
+// Running marks the event as StatusRunning.
+func (b *EventBuilder) Running() *EventBuilder { return b.Status(StatusRunning) }
+
+// WithMeta sets a metadata key.
+func (b *EventBuilder) WithMeta(k string, v any) *EventBuilder {
+	if b.e.Metadata == nil {
+		b.e.Metadata = make(map[string]any)
+	}
+	b.e.Metadata[k] = v
+	return b
+}
+
+// WithExtra sets an extension key.
+func (b *EventBuilder) WithExtra(k string, v any) *EventBuilder {
+	if b.e.Extra == nil {
+		b.e.Extra = make(map[string]any)
+	}
+	b.e.Extra[k] = v
+	return b
+}
+
+// Message sets the message without changing severity.
+func (b *EventBuilder) Message(msg string) *EventBuilder {
+	b.e.Message = msg
+	return b
+}
+
+// Debug sets the message with SeverityDebug.
+func (b *EventBuilder) Debug(msg string) *EventBuilder { return b.level(SeverityDebug, msg) }
+
+// Info sets the message with SeverityInfo.
+func (b *EventBuilder) Info(msg string) *EventBuilder { return b.level(SeverityInfo, msg) }
+
+// Warn sets the message with SeverityWarn.
+func (b *EventBuilder) Warn(msg string) *EventBuilder { return b.level(SeverityWarn, msg) }
+
+// Error sets the message with SeverityError.
+func (b *EventBuilder) Error(msg string) *EventBuilder { return b.level(SeverityError, msg) }
+
+// Debugf sets a formatted message with SeverityDebug.
+func (b *EventBuilder) Debugf(format string, args ...any) *EventBuilder {
+	return b.level(SeverityDebug, fmt.Sprintf(format, args...))
+}
+
+// Infof sets a formatted message with SeverityInfo.
+func (b *EventBuilder) Infof(format string, args ...any) *EventBuilder {
+	return b.level(SeverityInfo, fmt.Sprintf(format, args...))
+}
+
+// Warnf sets a formatted message with SeverityWarn.
+func (b *EventBuilder) Warnf(format string, args ...any) *EventBuilder {
+	return b.level(SeverityWarn, fmt.Sprintf(format, args...))
+}
+
+// Errorf sets a formatted message with SeverityError.
+func (b *EventBuilder) Errorf(format string, args ...any) *EventBuilder {
+	return b.level(SeverityError, fmt.Sprintf(format, args...))
+}
+
+func (b *EventBuilder) level(s Severity, msg string) *EventBuilder {
+	b.e.Severity = s
+	b.e.Message = msg
+	return b
+}
+
+// Build returns the assembled Event. Unset timestamps default to
+// time.Now().UTC(); an unset status defaults to StatusError for error
+// severity and StatusSuccess otherwise.
+func (b *EventBuilder) Build() Event {
+	e := b.e
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	if e.Status == "" {
+		if e.Severity == SeverityError {
+			e.Status = StatusError
+		} else {
+			e.Status = StatusSuccess
+		}
+	}
+	e.Metadata = maps.Clone(b.e.Metadata)
+	e.Extra = maps.Clone(b.e.Extra)
+	return e
+}
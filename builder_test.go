@@ -0,0 +1,80 @@
+package packtrack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventBuilder(t *testing.T) {
+	b := NewEvent().
+		Source("svc", "prod").
+		Actor("agent", "a1").
+		Workflow("wf").
+		ForRun("r1", "s1").
+		WithMeta("k", "v").
+		Errorf("failed %d times", 3)
+	e := b.Build()
+	if err := e.Validate(); err != nil {
+		t.Fatalf("built event invalid: %v", err)
+	}
+	if e.Message != "failed 3 times" || e.Severity != SeverityError || e.Status != StatusError {
+		t.Fatalf("unexpected event %+v", e)
+	}
+	if e.Workflow.RunID != "r1" || e.Workflow.StepID != "s1" {
+		t.Fatalf("unexpected workflow %+v", e.Workflow)
+	}
+	if e.Timestamp.IsZero() || e.Timestamp.Location().String() != "UTC" {
+		t.Fatalf("expected UTC timestamp, got %v", e.Timestamp)
+	}
+	// Builds are independent copies.
+	b.WithMeta("k2", 1)
+	if _, ok := e.Metadata["k2"]; ok {
+		t.Fatalf("builder mutation leaked into built event")
+	}
+	if got := NewEvent().Info("ok").Build().Status; got != StatusSuccess {
+		t.Fatalf("expected success status default, got %q", got)
+	}
+}
+
+func TestClientDefaults(t *testing.T) {
+	var got []Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	callerMeta := map[string]any{"own": 1, "team": "override"}
+	defMeta := map[string]any{"team": "core", "region": "eu"}
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithStrictValidation(),
+		WithDefaultSource(Source{System: "svc", Env: "prod"}),
+		WithDefaultActor(Actor{Type: "agent", ID: "a1", DisplayName: "Agent One"}),
+		WithDefaultMetadata(defMeta),
+	)
+	defMeta["region"] = "us"
+	ev := Event{Workflow: Workflow{ID: "wf"}, Severity: SeverityInfo, Status: StatusSuccess, Message: "m", Metadata: callerMeta}
+	own := ev
+	own.Source = Source{System: "other"}
+	own.Actor = Actor{Type: "user", ID: "u1"}
+	if _, err := c.IngestBatch(context.Background(), []Event{ev, own}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	if got[1].Source != (Source{System: "other"}) || got[1].Actor != (Actor{Type: "user", ID: "u1"}) {
+		t.Fatalf("default identity mixed into event's own: %+v %+v", got[1].Source, got[1].Actor)
+	}
+	e := got[0]
+	if e.Source.System != "svc" || e.Source.Env != "prod" || e.Actor.ID != "a1" || e.Timestamp.IsZero() {
+		t.Fatalf("defaults not applied: %+v", e)
+	}
+	if e.Metadata["team"] != "override" || e.Metadata["region"] != "eu" {
+		t.Fatalf("unexpected metadata %v", e.Metadata)
+	}
+	if _, ok := callerMeta["region"]; ok {
+		t.Fatalf("caller metadata map was mutated")
+	}
+}
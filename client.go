@@ -5,8 +5,10 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"strings"
 	"time"
@@ -55,19 +57,51 @@ func NewClient(opts ...Option) (Client, error) {
 }
 
 func (c *client) IngestEvent(ctx context.Context, e Event) (IngestResponse, error) {
+	keep, err := c.prepareEvent(ctx, &e)
+	if err != nil || !keep {
+		return IngestResponse{}, err
+	}
 	payload, err := json.Marshal(e)
 	if err != nil {
 		return IngestResponse{}, fmt.Errorf("marshal event: %w", err)
 	}
-	return c.send(ctx, payload, false)
+	return c.send(ctx, payload, 1)
 }
 
 func (c *client) IngestBatch(ctx context.Context, events []Event) (IngestResponse, error) {
+	prepared := make([]Event, 0, len(events))
+	var invalid *BatchValidationError
+	for i, e := range events {
+		keep, err := c.prepareEvent(ctx, &e)
+		if err != nil {
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				return IngestResponse{}, err
+			}
+			invalid = invalid.add(i, ve)
+			continue
+		}
+		if keep {
+			prepared = append(prepared, e)
+		}
+	}
+	if invalid != nil {
+		return IngestResponse{}, invalid
+	}
+	return c.ingestPrepared(ctx, prepared)
+}
+
+// ingestPrepared sends events that already went through prepareEvent.
+// Empty batches are not sent.
+func (c *client) ingestPrepared(ctx context.Context, events []Event) (IngestResponse, error) {
+	if len(events) == 0 {
+		return IngestResponse{}, nil
+	}
 	payload, err := json.Marshal(events)
 	if err != nil {
 		return IngestResponse{}, fmt.Errorf("marshal batch: %w", err)
 	}
-	return c.send(ctx, payload, true)
+	return c.send(ctx, payload, len(events))
 }
 
 func (c *client) HealthCheck(ctx context.Context) bool {
@@ -88,11 +122,105 @@ func (c *client) HealthCheck(ctx context.Context) bool {
 	return resp.StatusCode >= 200 && resp.StatusCode < 300
 }
 
+// prepareEvent fills the context workflow and client defaults, applies the
+// severity threshold, sampler, and processors, redacts, enforces size limits,
+// and runs strict validation when enabled. It returns keep=false for events
+// that were dropped (and counted). AsyncClient calls it at Enqueue so
+// timestamps reflect enqueue time, filtered events never occupy the queue, and
+// one invalid event cannot fail a whole batch later.
+func (c *client) prepareEvent(ctx context.Context, e *Event) (keep bool, err error) {
+	fillWorkflow(ctx, e)
+	c.applyDefaults(e)
+	if !c.keepSeverity(*e) {
+		return false, nil
+	}
+	if c.cfg.Sampler != nil && !c.cfg.Sampler.Sample(e) {
+		c.cfg.MetricsHooks.dropped(DropReasonSampled, 1)
+		return false, nil
+	}
+	if !c.runProcessors(ctx, e) {
+		return false, nil
+	}
+	if c.cfg.Redactor != nil {
+		c.cfg.Redactor.Redact(e)
+	}
+	if c.cfg.Limits.enabled() {
+		c.cfg.MetricsHooks.truncated(c.cfg.Limits.enforce(e))
+	}
+	if c.cfg.StrictValidation {
+		if err := e.Validate(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// keepSeverity reports whether e meets the applicable minimum severity,
+// counting the drop otherwise. Without a minimum every event is kept,
+// including unset and unknown severities.
+func (c *client) keepSeverity(e Event) bool {
+	min, ok := c.cfg.WorkflowMinSeverity[e.Workflow.ID]
+	if !ok {
+		min, ok = c.cfg.SourceMinSeverity[e.Source.System]
+	}
+	if !ok {
+		min = c.cfg.MinSeverity
+	}
+	if min == 0 || e.Severity >= min {
+		return true
+	}
+	c.cfg.MetricsHooks.dropped(DropReasonSeverity, 1)
+	return false
+}
+
+// applyDefaults fills empty fields from the client defaults. Source and Actor
+// defaults apply only to events that leave Source.System or Actor.ID empty, so
+// an event naming its own system or actor never inherits another identity's
+// fields. It never mutates the caller's Metadata map.
+func (c *client) applyDefaults(e *Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	if e.Source.System == "" {
+		def := c.cfg.DefaultSource
+		e.Source.System = def.System
+		if e.Source.Env == "" {
+			e.Source.Env = def.Env
+		}
+	}
+	if e.Actor.ID == "" {
+		def := c.cfg.DefaultActor
+		e.Actor.ID = def.ID
+		if e.Actor.Type == "" {
+			e.Actor.Type = def.Type
+		}
+		if e.Actor.DisplayName == "" {
+			e.Actor.DisplayName = def.DisplayName
+		}
+	}
+	var md map[string]any
+	for k, v := range c.cfg.DefaultMetadata {
+		if _, ok := e.Metadata[k]; ok {
+			continue
+		}
+		if md == nil {
+			md = make(map[string]any, len(e.Metadata)+len(c.cfg.DefaultMetadata))
+			maps.Copy(md, e.Metadata)
+		}
+		md[k] = v
+	}
+	if md != nil {
+		e.Metadata = md
+	}
+}
+
 func (c *client) Flush(ctx context.Context) error { return nil }
 func (c *client) Close(ctx context.Context) error { c.closed = true; return nil }
 
-func (c *client) send(ctx context.Context, payload []byte, isBatch bool) (IngestResponse, error) {
-	var body io.Reader = bytes.NewReader(payload)
+// send posts payload, which holds count events, retrying per c.cfg.Retry.
+func (c *client) send(ctx context.Context, payload []byte, count int) (IngestResponse, error) {
+	hooks := c.cfg.MetricsHooks
+	body := payload
 	var contentEncoding string
 	if c.cfg.Compression == CompressionGzip {
 		var buf bytes.Buffer
@@ -103,40 +231,43 @@ func (c *client) send(ctx context.Context, payload []byte, isBatch bool) (Ingest
 		if err := zw.Close(); err != nil {
 			return IngestResponse{}, fmt.Errorf("gzip close: %w", err)
 		}
-		body = &buf
+		body = buf.Bytes()
 		contentEncoding = "gzip"
 	}
+	hooks.payload(len(payload), len(body))
 
 	url := strings.TrimRight(c.cfg.BaseURL, "/") + "/api/ingest"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
-	if err != nil {
-		return IngestResponse{}, fmt.Errorf("build request: %w", err)
-	}
-	c.addCommonHeaders(req)
-	req.Header.Set("Content-Type", "application/json")
-	if contentEncoding != "" {
-		req.Header.Set("Content-Encoding", contentEncoding)
-	}
-	if c.cfg.IdempotencyKey != "" {
-		req.Header.Set("Idempotency-Key", c.cfg.IdempotencyKey)
-	}
-
 	attempts := c.cfg.Retry.MaxAttempts
 	if attempts <= 0 {
 		attempts = 1
 	}
 	var lastErr error
 	for i := 0; i < attempts; i++ {
+		// A fresh request per attempt, so retries resend the full body.
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return IngestResponse{}, fmt.Errorf("build request: %w", err)
+		}
+		c.addCommonHeaders(req)
+		req.Header.Set("Content-Type", "application/json")
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		if c.cfg.IdempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", c.cfg.IdempotencyKey)
+		}
+
+		start := time.Now()
 		resp, err := c.http.Do(req)
 		if err != nil {
+			hooks.request(time.Since(start), 0)
 			lastErr = &IngestError{Retryable: true, Cause: err}
 		} else {
 			b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1MB cap
 			resp.Body.Close()
+			hooks.request(time.Since(start), resp.StatusCode)
 			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-				if c.cfg.MetricsHooks != nil && c.cfg.MetricsHooks.OnIngestSuccess != nil {
-					c.cfg.MetricsHooks.OnIngestSuccess(1)
-				}
+				hooks.ingestSuccess(count)
 				return IngestResponse{StatusCode: resp.StatusCode, Body: b}, nil
 			}
 			retryable := resp.StatusCode >= 500 || resp.StatusCode == 429
@@ -147,16 +278,29 @@ func (c *client) send(ctx context.Context, payload []byte, isBatch bool) (Ingest
 		if ie != nil && !ie.Retryable {
 			break
 		}
+		if ctx.Err() != nil {
+			break
+		}
 		if i < attempts-1 {
+			hooks.retry(i+1, retryReason(ie))
 			c.sleepBackoff(i)
 		}
 	}
-	if c.cfg.MetricsHooks != nil && c.cfg.MetricsHooks.OnIngestFailure != nil {
-		c.cfg.MetricsHooks.OnIngestFailure(1)
-	}
+	hooks.ingestFailure(count)
 	return IngestResponse{}, lastErr
 }
 
+func retryReason(ie *IngestError) string {
+	switch {
+	case ie == nil || ie.StatusCode == 0:
+		return RetryReasonNetwork
+	case ie.StatusCode == http.StatusTooManyRequests:
+		return RetryReasonRateLimited
+	default:
+		return RetryReasonServerError
+	}
+}
+
 func (c *client) sleepBackoff(attempt int) {
 	// Exponential backoff with jitter
 	backoff := c.cfg.Retry.InitialBackoff
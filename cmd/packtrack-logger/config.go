@@ -53,6 +53,9 @@ type Config struct {
 	BatchSize     int
 	FlushInterval time.Duration
 	QueueCapacity int
+
+	// Receiver
+	OTLPListen string
 }
 
 func envOrDefault(key, def string) string {
@@ -150,4 +153,7 @@ func (c *Config) LoadEnvDefaults() {
 	c.BatchSize = envInt("PACKTRACK_BATCH_SIZE", c.BatchSize)
 	c.FlushInterval = envDuration("PACKTRACK_FLUSH_INTERVAL", c.FlushInterval)
 	c.QueueCapacity = envInt("PACKTRACK_QUEUE_CAPACITY", c.QueueCapacity)
+
+	// Receiver
+	c.OTLPListen = envOrDefault("PACKTRACK_OTLP_LISTEN", c.OTLPListen)
 }
@@ -41,10 +41,8 @@ func buildEventFromConfig(cfg *Config) (packtrack.Event, error) {
 		return packtrack.Event{}, fmt.Errorf("missing required --message")
 	}
 
-	sev := packtrack.Severity(cfg.Severity)
-	switch sev {
-	case packtrack.SeverityDebug, packtrack.SeverityInfo, packtrack.SeverityWarn, packtrack.SeverityError:
-	default:
+	sev, err := packtrack.ParseSeverity(cfg.Severity)
+	if err != nil {
 		return packtrack.Event{}, fmt.Errorf("invalid --severity: %s", cfg.Severity)
 	}
 	st := packtrack.Status(cfg.Status)
@@ -58,6 +58,9 @@ func main() {
 	flag.DurationVar(&cfg.FlushInterval, "flush-interval", time.Second, "async flush interval")
 	flag.IntVar(&cfg.QueueCapacity, "queue-capacity", 10000, "async queue capacity")
 
+	// Receiver
+	flag.StringVar(&cfg.OTLPListen, "otlp-listen", cfg.OTLPListen, "receive OTLP/HTTP JSON logs on this address (e.g. 127.0.0.1:4318) and forward them until interrupted")
+
 	// Misc
 	showVersion := flag.Bool("version", false, "print version and exit")
 
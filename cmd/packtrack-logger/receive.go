@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/packtrackotlp"
+)
+
+// runReceiver listens for OTLP/HTTP JSON logs on cfg.OTLPListen and forwards
+// them through the async client until interrupted. Event fields from flags
+// and environment fill what the OTLP records leave empty.
+func runReceiver(cfg *Config) int {
+	cl, err := buildClient(cfg,
+		packtrack.WithDefaultSource(packtrack.Source{System: cfg.SourceSystem, Env: cfg.SourceEnv}),
+		packtrack.WithDefaultActor(packtrack.Actor{Type: cfg.ActorType, ID: cfg.ActorID, DisplayName: cfg.ActorDisplay}))
+	if err != nil {
+		fmt.Fprintf(stderr(), "error: %v\n", err)
+		return ExitInvalid
+	}
+	ac, err := packtrack.NewAsyncClient(cl,
+		packtrack.WithBatchSize(nonZeroInt(cfg.BatchSize, 100)),
+		packtrack.WithFlushInterval(nonZeroDuration(cfg.FlushInterval, time.Second)),
+		packtrack.WithQueueCapacity(nonZeroInt(cfg.QueueCapacity, 10000)))
+	if err != nil {
+		fmt.Fprintf(stderr(), "error: %v\n", err)
+		return ExitInvalid
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(packtrackotlp.LogsPath, packtrackotlp.NewHandler(ac, packtrackotlp.WithDefaultWorkflow(packtrack.Workflow{
+		ID: cfg.WorkflowID, Name: cfg.WorkflowName, RunID: cfg.RunID, StepID: cfg.StepID,
+	})))
+	ln, err := net.Listen("tcp", cfg.OTLPListen)
+	if err != nil {
+		fmt.Fprintf(stderr(), "listen error: %v\n", err)
+		_ = ac.Close(context.Background())
+		return ExitInvalid
+	}
+	if cfg.Verbose {
+		fmt.Fprintf(stderr(), "receiving OTLP logs on http://%s%s\n", ln.Addr(), packtrackotlp.LogsPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+
+	code := ExitOK
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(stderr(), "serve error: %v\n", err)
+			code = ExitSDKError
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), nonZeroDuration(cfg.Timeout, 15*time.Second))
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+	if err := ac.Close(shutdownCtx); err != nil {
+		fmt.Fprintf(stderr(), "close error: %v\n", err)
+		return classifyErr(err)
+	}
+	return code
+}
@@ -18,7 +18,7 @@ const (
 	ExitInputError   = 4
 )
 
-func buildClient(cfg *Config) (packtrack.Client, error) {
+func buildClient(cfg *Config, extra ...packtrack.Option) (packtrack.Client, error) {
 	opts := []packtrack.Option{
 		packtrack.WithBaseURL(nonEmpty(cfg.BaseURL, packtrack.Defaults().BaseURL)),
 		packtrack.WithAPIKey(cfg.APIKey),
@@ -43,7 +43,7 @@ func buildClient(cfg *Config) (packtrack.Client, error) {
 	if cfg.HealthPath != "" {
 		opts = append(opts, packtrack.WithHealthPath(cfg.HealthPath))
 	}
-	return packtrack.NewClient(opts...)
+	return packtrack.NewClient(append(opts, extra...)...)
 }
 
 func nonEmpty(s, def string) string {
@@ -93,6 +93,10 @@ func runCLI(cfg *Config) int {
 		return ExitInvalid
 	}
 
+	if cfg.OTLPListen != "" {
+		return runReceiver(cfg)
+	}
+
 	// Input source
 	var events []packtrack.Event
 	if cfg.File != "" {
@@ -120,6 +124,10 @@ func runCLI(cfg *Config) int {
 	}
 
 	if cfg.DryRun {
+		if err := packtrack.ValidateBatch(events); err != nil {
+			fmt.Fprintf(stderr(), "invalid: %v\n", err)
+			return ExitInvalid
+		}
 		if cfg.Verbose {
 			fmt.Fprintln(stderr(), "dry-run: validation passed")
 		}
@@ -192,6 +200,11 @@ func runCLI(cfg *Config) int {
 }
 
 func classifyErr(err error) int {
+	var ve *packtrack.ValidationError
+	var bve *packtrack.BatchValidationError
+	if errors.As(err, &ve) || errors.As(err, &bve) {
+		return ExitInvalid
+	}
 	var ie *packtrack.IngestError
 	if errors.As(err, &ie) {
 		if ie.Retryable {
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds mock server configuration.
+type Config struct {
+	// Listener
+	Addr string
+
+	// Auth: when APIKey is non-empty, requests must carry a matching X-PackTrack-Key.
+	APIKey string
+
+	// Fault injection
+	Latency       time.Duration
+	LatencyJitter time.Duration
+	ErrorRate     float64 // 0..1 fraction of ingest requests answered with 500
+	ThrottleEvery int     // after this many ingest requests, start a 429 burst (0 disables)
+	ThrottleBurst int     // number of consecutive 429 responses per burst
+	RetryAfter    time.Duration
+
+	// Payload limits
+	MaxBodyBytes int64
+	MaxBatch     int
+
+	// Storage
+	OutFile string
+	Retain  int
+
+	Verbose bool
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	case "0", "false", "no", "n", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+// LoadEnvDefaults populates defaults from environment variables.
+func (c *Config) LoadEnvDefaults() {
+	c.Addr = envOrDefault("PACKTRACK_MOCK_ADDR", c.Addr)
+	c.APIKey = envOrDefault("PACKTRACK_MOCK_API_KEY", c.APIKey)
+
+	c.Latency = envDuration("PACKTRACK_MOCK_LATENCY", c.Latency)
+	c.LatencyJitter = envDuration("PACKTRACK_MOCK_LATENCY_JITTER", c.LatencyJitter)
+	c.ErrorRate = envFloat("PACKTRACK_MOCK_ERROR_RATE", c.ErrorRate)
+	c.ThrottleEvery = envInt("PACKTRACK_MOCK_THROTTLE_EVERY", c.ThrottleEvery)
+	c.ThrottleBurst = envInt("PACKTRACK_MOCK_THROTTLE_BURST", c.ThrottleBurst)
+	c.RetryAfter = envDuration("PACKTRACK_MOCK_RETRY_AFTER", c.RetryAfter)
+
+	c.MaxBodyBytes = int64(envInt("PACKTRACK_MOCK_MAX_BODY_BYTES", int(c.MaxBodyBytes)))
+	c.MaxBatch = envInt("PACKTRACK_MOCK_MAX_BATCH", c.MaxBatch)
+
+	c.OutFile = envOrDefault("PACKTRACK_MOCK_OUT", c.OutFile)
+	c.Retain = envInt("PACKTRACK_MOCK_RETAIN", c.Retain)
+
+	c.Verbose = envBool("PACKTRACK_MOCK_VERBOSE", c.Verbose)
+}
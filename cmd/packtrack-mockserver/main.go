@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	cfg := &Config{
+		Addr:         "127.0.0.1:8787",
+		RetryAfter:   time.Second,
+		MaxBodyBytes: 5 << 20,
+		Retain:       10000,
+	}
+	// Load env defaults before defining flags so flags get env-backed defaults
+	cfg.LoadEnvDefaults()
+
+	flag.StringVar(&cfg.Addr, "addr", cfg.Addr, "listen address")
+	flag.StringVar(&cfg.APIKey, "api-key", cfg.APIKey, "require this X-PackTrack-Key (empty accepts any)")
+
+	// Fault injection
+	flag.DurationVar(&cfg.Latency, "latency", cfg.Latency, "added latency per ingest request")
+	flag.DurationVar(&cfg.LatencyJitter, "latency-jitter", cfg.LatencyJitter, "random extra latency in [0, jitter)")
+	flag.Float64Var(&cfg.ErrorRate, "error-rate", cfg.ErrorRate, "fraction 0..1 of ingest requests answered with 500")
+	flag.IntVar(&cfg.ThrottleEvery, "throttle-every", cfg.ThrottleEvery, "start a 429 burst every N ingest requests (0 disables)")
+	flag.IntVar(&cfg.ThrottleBurst, "throttle-burst", defaultInt(cfg.ThrottleBurst, 1), "consecutive 429 responses per burst")
+	flag.DurationVar(&cfg.RetryAfter, "retry-after", cfg.RetryAfter, "Retry-After value sent with 429 responses")
+
+	// Limits
+	flag.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", cfg.MaxBodyBytes, "maximum request body size, compressed and decompressed (0 disables)")
+	flag.IntVar(&cfg.MaxBatch, "max-batch", cfg.MaxBatch, "maximum events per request (0 disables)")
+
+	// Storage
+	flag.StringVar(&cfg.OutFile, "out", cfg.OutFile, "append received events to this NDJSON file")
+	flag.IntVar(&cfg.Retain, "retain", cfg.Retain, "events kept in memory for GET /api/events (0 keeps all)")
+
+	flag.BoolVar(&cfg.Verbose, "verbose", cfg.Verbose, "log requests to stderr")
+	showVersion := flag.Bool("version", false, "print version and exit")
+
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("packtrack-mockserver %s\n", Version)
+		os.Exit(0)
+	}
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 1 {
+		fmt.Fprintln(os.Stderr, "error: --error-rate must be within 0..1")
+		os.Exit(1)
+	}
+
+	if err := run(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg *Config) error {
+	st, err := newStore(cfg.Retain, cfg.OutFile)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           newServer(cfg, st).routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("packtrack-mockserver listening on %s", cfg.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func defaultInt(got, def int) int {
+	if got > 0 {
+		return got
+	}
+	return def
+}
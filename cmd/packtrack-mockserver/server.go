@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type server struct {
+	cfg   *Config
+	store *store
+	logf  func(format string, args ...any)
+
+	mu       sync.Mutex
+	requests int // ingest requests seen, drives 429 bursts
+	burst    int // remaining 429 responses in the current burst
+}
+
+func newServer(cfg *Config, st *store) *server {
+	s := &server{cfg: cfg, store: st, logf: func(string, ...any) {}}
+	if cfg.Verbose {
+		s.logf = log.Printf
+	}
+	return s
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/ingest", s.handleIngest)
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("DELETE /api/events", s.handleReset)
+	return mux
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	retained, total := s.store.stats()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   "ok",
+		"version":  Version,
+		"retained": retained,
+		"received": total,
+	})
+}
+
+func (s *server) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if !s.sleep(r) {
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or missing X-PackTrack-Key")
+		return
+	}
+	if s.throttled() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.cfg.RetryAfter.Seconds())))
+		writeError(w, http.StatusTooManyRequests, "rate limited")
+		return
+	}
+	if s.cfg.ErrorRate > 0 && rand.Float64() < s.cfg.ErrorRate {
+		writeError(w, http.StatusInternalServerError, "injected failure")
+		return
+	}
+
+	body, status, err := s.readBody(w, r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	events, err := decodeEvents(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.cfg.MaxBatch > 0 && len(events) > s.cfg.MaxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("batch of %d events exceeds limit %d", len(events), s.cfg.MaxBatch))
+		return
+	}
+	if err := s.store.add(events); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.logf("ingest: accepted %d event(s)", len(events))
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "accepted": len(events)})
+}
+
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or missing X-PackTrack-Key")
+		return
+	}
+	q := r.URL.Query()
+	f := eventFilter{
+		WorkflowID: q.Get("workflow_id"),
+		RunID:      q.Get("run_id"),
+		System:     q.Get("source_system"),
+		Limit:      100,
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		f.Limit = n
+	}
+	events := s.store.query(f)
+	if events == nil {
+		events = []json.RawMessage{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (s *server) handleReset(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or missing X-PackTrack-Key")
+		return
+	}
+	s.store.reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) authorized(r *http.Request) bool {
+	return s.cfg.APIKey == "" || r.Header.Get("X-PackTrack-Key") == s.cfg.APIKey
+}
+
+// sleep applies the configured latency. It returns false if the client went away.
+func (s *server) sleep(r *http.Request) bool {
+	d := s.cfg.Latency
+	if s.cfg.LatencyJitter > 0 {
+		d += time.Duration(rand.Int64N(int64(s.cfg.LatencyJitter)))
+	}
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// throttled reports whether this request falls inside a 429 burst.
+func (s *server) throttled() bool {
+	if s.cfg.ThrottleEvery <= 0 || s.cfg.ThrottleBurst <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.burst > 0 {
+		s.burst--
+		return true
+	}
+	s.requests++
+	if s.requests%s.cfg.ThrottleEvery == 0 {
+		s.burst = s.cfg.ThrottleBurst - 1
+		return true
+	}
+	return false
+}
+
+func (s *server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	var body io.Reader = r.Body
+	if s.cfg.MaxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes)
+	}
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		body = zr
+		if s.cfg.MaxBodyBytes > 0 {
+			// Apply the limit to the decompressed size as well.
+			body = io.LimitReader(zr, s.cfg.MaxBodyBytes+1)
+		}
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", mbe.Limit)
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("read body: %w", err)
+	}
+	if s.cfg.MaxBodyBytes > 0 && int64(len(b)) > s.cfg.MaxBodyBytes {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", s.cfg.MaxBodyBytes)
+	}
+	return b, 0, nil
+}
+
+// decodeEvents accepts a single JSON object or an array of objects.
+func decodeEvents(b []byte) ([]json.RawMessage, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.New("empty body")
+	}
+	var events []json.RawMessage
+	switch b[0] {
+	case '[':
+		if err := json.Unmarshal(b, &events); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+	case '{':
+		if !json.Valid(b) {
+			return nil, errors.New("invalid JSON object")
+		}
+		events = []json.RawMessage{b}
+	default:
+		return nil, errors.New("body must be a JSON event or array of events")
+	}
+	for i, e := range events {
+		e = bytes.TrimSpace(e)
+		if len(e) == 0 || e[0] != '{' {
+			return nil, fmt.Errorf("event %d is not a JSON object", i)
+		}
+	}
+	return events, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]any{"ok": false, "error": msg})
+}
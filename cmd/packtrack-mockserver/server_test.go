@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, cfg *Config) *httptest.Server {
+	t.Helper()
+	st, err := newStore(cfg.Retain, cfg.OutFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	ts := httptest.NewServer(newServer(cfg, st).routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func do(t *testing.T, method, url, key string, body []byte, header ...string) (int, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, bytes.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-PackTrack-Key", key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	return resp.StatusCode, buf.Bytes()
+}
+
+const (
+	evA = `{"source":{"system":"svc-a"},"workflow":{"id":"wf-1","run_id":"r1"},"message":"a","future_field":1}`
+	evB = `{"source":{"system":"svc-b"},"workflow":{"id":"wf-2"},"message":"b"}`
+)
+
+func TestIngestAndQuery(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "events.ndjson")
+	ts := newTestServer(t, &Config{APIKey: "k", OutFile: out})
+
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "", []byte(evA)); code != http.StatusUnauthorized {
+		t.Errorf("missing key: status %d", code)
+	}
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "k", []byte(evA)); code != http.StatusOK {
+		t.Fatalf("single event: status %d", code)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("[" + evA + "," + evB + "]"))
+	zw.Close()
+	code, body := do(t, "POST", ts.URL+"/api/ingest", "k", gz.Bytes(), "Content-Encoding", "gzip")
+	if code != http.StatusOK || !strings.Contains(string(body), `"accepted":2`) {
+		t.Fatalf("gzip batch: status %d body %s", code, body)
+	}
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "k", []byte(`[1]`)); code != http.StatusBadRequest {
+		t.Errorf("non-object event: status %d", code)
+	}
+
+	code, body = do(t, "GET", ts.URL+"/api/events?workflow_id=wf-1", "k", nil)
+	var got []map[string]any
+	if err := json.Unmarshal(body, &got); err != nil || code != http.StatusOK {
+		t.Fatalf("query: status %d body %s", code, body)
+	}
+	if len(got) != 2 || got[0]["future_field"] != float64(1) {
+		t.Errorf("query wf-1 = %s", body)
+	}
+	if _, body := do(t, "GET", ts.URL+"/api/events?source_system=svc-b&limit=1", "k", nil); !strings.Contains(string(body), `"message":"b"`) {
+		t.Errorf("query svc-b = %s", body)
+	}
+	if code, _ := do(t, "GET", ts.URL+"/api/events?limit=x", "k", nil); code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status %d", code)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 3 || lines[0] != evA {
+		t.Errorf("NDJSON file =\n%s", b)
+	}
+
+	if code, _ := do(t, "DELETE", ts.URL+"/api/events", "k", nil); code != http.StatusNoContent {
+		t.Errorf("reset: status %d", code)
+	}
+	_, body = do(t, "GET", ts.URL+"/api/health", "", nil)
+	var health map[string]any
+	json.Unmarshal(body, &health)
+	if health["retained"] != float64(0) || health["received"] != float64(0) {
+		t.Errorf("health after reset = %s", body)
+	}
+}
+
+func TestThrottleBursts(t *testing.T) {
+	ts := newTestServer(t, &Config{ThrottleEvery: 3, ThrottleBurst: 2, RetryAfter: 2e9})
+	var codes []int
+	for range 6 {
+		req, _ := http.NewRequest("POST", ts.URL+"/api/ingest", strings.NewReader(evA))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		codes = append(codes, resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "2" {
+			t.Errorf("Retry-After = %q", resp.Header.Get("Retry-After"))
+		}
+	}
+	want := []int{200, 200, 429, 429, 200, 200}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("codes = %v, want %v", codes, want)
+		}
+	}
+}
+
+func TestPayloadLimits(t *testing.T) {
+	ts := newTestServer(t, &Config{MaxBodyBytes: 64, MaxBatch: 1})
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "", []byte("["+evB+","+evB+"]")); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: status %d", code)
+	}
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "", []byte(`[{},{}]`)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized batch: status %d", code)
+	}
+
+	// The limit also applies after decompression.
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"message":"` + strings.Repeat("x", 200) + `"}`))
+	zw.Close()
+	if code, _ := do(t, "POST", ts.URL+"/api/ingest", "", gz.Bytes(), "Content-Encoding", "gzip"); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("gzip bomb: status %d", code)
+	}
+}
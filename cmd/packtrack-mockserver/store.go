@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// storedEvent is a received event kept verbatim so newer schema fields survive.
+type storedEvent struct {
+	ReceivedAt time.Time
+	Raw        json.RawMessage
+}
+
+// eventFilter selects events for the read endpoint.
+type eventFilter struct {
+	WorkflowID string
+	RunID      string
+	System     string
+	Limit      int
+}
+
+// store keeps the most recent events in memory and optionally appends every
+// event to an NDJSON file.
+type store struct {
+	mu     sync.Mutex
+	events []storedEvent
+	retain int
+	total  int
+	out    io.WriteCloser
+}
+
+func newStore(retain int, outFile string) (*store, error) {
+	s := &store{retain: retain}
+	if outFile != "" {
+		f, err := os.OpenFile(outFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", outFile, err)
+		}
+		s.out = f
+	}
+	return s, nil
+}
+
+// add records events received in a single request.
+func (s *store) add(events []json.RawMessage) error {
+	now := time.Now().UTC()
+	var lines bytes.Buffer
+	compacted := make([]json.RawMessage, 0, len(events))
+	for _, raw := range events {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return err
+		}
+		compacted = append(compacted, buf.Bytes())
+		lines.Write(buf.Bytes())
+		lines.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out != nil {
+		if _, err := s.out.Write(lines.Bytes()); err != nil {
+			return fmt.Errorf("persist events: %w", err)
+		}
+	}
+	for _, raw := range compacted {
+		s.events = append(s.events, storedEvent{ReceivedAt: now, Raw: raw})
+	}
+	s.total += len(compacted)
+	if s.retain > 0 && len(s.events) > s.retain {
+		// Drop the oldest; copy so the backing array does not grow forever.
+		s.events = append([]storedEvent(nil), s.events[len(s.events)-s.retain:]...)
+	}
+	return nil
+}
+
+// query returns the newest matching events in receive order.
+func (s *store) query(f eventFilter) []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []json.RawMessage
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(res) >= f.Limit {
+			break
+		}
+		if !f.matches(s.events[i].Raw) {
+			continue
+		}
+		res = append(res, s.events[i].Raw)
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+func (s *store) stats() (retained, total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events), s.total
+}
+
+// reset forgets retained events and the received count. Events already
+// persisted to the NDJSON file are kept.
+func (s *store) reset() {
+	s.mu.Lock()
+	s.events = nil
+	s.total = 0
+	s.mu.Unlock()
+}
+
+func (s *store) Close() error {
+	if s.out == nil {
+		return nil
+	}
+	return s.out.Close()
+}
+
+func (f eventFilter) matches(raw json.RawMessage) bool {
+	if f.WorkflowID == "" && f.RunID == "" && f.System == "" {
+		return true
+	}
+	var ev struct {
+		Source struct {
+			System string `json:"system"`
+		} `json:"source"`
+		Workflow struct {
+			ID    string `json:"id"`
+			RunID string `json:"run_id"`
+		} `json:"workflow"`
+	}
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return false
+	}
+	if f.WorkflowID != "" && ev.Workflow.ID != f.WorkflowID {
+		return false
+	}
+	if f.RunID != "" && ev.Workflow.RunID != f.RunID {
+		return false
+	}
+	if f.System != "" && ev.Source.System != f.System {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package packtrack
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Metadata keys added by HostEnricher and KubernetesEnricher.
+const (
+	MetaHostName        = "host.name"
+	MetaHostPID         = "host.pid"
+	MetaHostGoVersion   = "host.go_version"
+	MetaHostSDKVersion  = "host.sdk_version"
+	MetaHostBuildPath   = "host.build.path"
+	MetaHostBuildVer    = "host.build.version"
+	MetaHostBuildVCSRev = "host.build.vcs_revision"
+	MetaHostContainerID = "host.container_id"
+
+	MetaK8sPod       = "k8s.pod"
+	MetaK8sNamespace = "k8s.namespace"
+	MetaK8sNode      = "k8s.node"
+	MetaK8sPodUID    = "k8s.pod_uid"
+)
+
+// staticEnricher adds a fixed set of metadata keys that the event does not
+// already set.
+type staticEnricher struct {
+	values map[string]any
+}
+
+func (s staticEnricher) Process(_ context.Context, e *Event) (bool, error) {
+	for k, v := range s.values {
+		if _, ok := e.Metadata[k]; ok {
+			continue
+		}
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]any, len(s.values))
+		}
+		e.Metadata[k] = v
+	}
+	return true, nil
+}
+
+var hostInfo = sync.OnceValue(func() map[string]any {
+	m := map[string]any{
+		MetaHostPID:        os.Getpid(),
+		MetaHostGoVersion:  runtime.Version(),
+		MetaHostSDKVersion: Version,
+	}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		m[MetaHostName] = h
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Path != "" {
+			m[MetaHostBuildPath] = bi.Main.Path
+		}
+		if bi.Main.Version != "" {
+			m[MetaHostBuildVer] = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				m[MetaHostBuildVCSRev] = s.Value
+			}
+		}
+	}
+	if id := readContainerID(); id != "" {
+		m[MetaHostContainerID] = id
+	}
+	return m
+})
+
+// HostEnricher returns a processor adding host.* metadata: hostname, PID, Go
+// version, SDK version, main module build info, and the container ID when
+// running in a container. Values are computed once per process. Keys the
+// event already sets are left alone.
+func HostEnricher() EventProcessor { return staticEnricher{values: hostInfo()} }
+
+// KubernetesEnricher returns a processor adding k8s.* metadata from
+// downward-API environment variables, read once when it is constructed:
+//
+//	k8s.pod       K8S_POD_NAME or POD_NAME
+//	k8s.namespace K8S_NAMESPACE, K8S_POD_NAMESPACE, or POD_NAMESPACE
+//	k8s.node      K8S_NODE_NAME or NODE_NAME
+//	k8s.pod_uid   K8S_POD_UID or POD_UID
+//
+// Unset variables are omitted. Keys the event already sets are left alone.
+func KubernetesEnricher() EventProcessor {
+	m := make(map[string]any)
+	for key, envs := range map[string][]string{
+		MetaK8sPod:       {"K8S_POD_NAME", "POD_NAME"},
+		MetaK8sNamespace: {"K8S_NAMESPACE", "K8S_POD_NAMESPACE", "POD_NAMESPACE"},
+		MetaK8sNode:      {"K8S_NODE_NAME", "NODE_NAME"},
+		MetaK8sPodUID:    {"K8S_POD_UID", "POD_UID"},
+	} {
+		for _, env := range envs {
+			if v := os.Getenv(env); v != "" {
+				m[key] = v
+				break
+			}
+		}
+	}
+	return staticEnricher{values: m}
+}
+
+var (
+	cgroupIDRe    = regexp.MustCompile(`[0-9a-f]{64}`)
+	mountinfoIDRe = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+func readContainerID() string {
+	if f, err := os.Open("/proc/self/cgroup"); err == nil {
+		id := parseCgroupContainerID(f)
+		f.Close()
+		if id != "" {
+			return id
+		}
+	}
+	// cgroup v2 hosts often show only "0::/"; fall back to mountinfo.
+	if f, err := os.Open("/proc/self/mountinfo"); err == nil {
+		defer f.Close()
+		return parseMountinfoContainerID(f)
+	}
+	return ""
+}
+
+// parseCgroupContainerID returns the last 64-hex-digit ID in /proc/self/cgroup
+// content, covering docker, containerd, CRI-O, and kubepods layouts.
+func parseCgroupContainerID(r io.Reader) string {
+	var id string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if m := cgroupIDRe.FindAllString(s.Text(), -1); len(m) > 0 {
+			id = m[len(m)-1]
+		}
+	}
+	return id
+}
+
+func parseMountinfoContainerID(r io.Reader) string {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if m := mountinfoIDRe.FindStringSubmatch(s.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
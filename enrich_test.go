@@ -0,0 +1,61 @@
+package packtrack
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testContainerID = "3f4e1c1b2a9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f"
+
+func TestParseCgroupContainerID(t *testing.T) {
+	cases := []string{
+		"12:pids:/docker/" + testContainerID + "\n0::/\n",
+		"0::/system.slice/docker-" + testContainerID + ".scope\n",
+		"0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1a2b.slice/cri-containerd-" + testContainerID + ".scope\n",
+		"11:memory:/kubepods/burstable/pod7a1b/" + testContainerID + "\n",
+	}
+	for _, c := range cases {
+		if got := parseCgroupContainerID(strings.NewReader(c)); got != testContainerID {
+			t.Fatalf("parse %q = %q", c, got)
+		}
+	}
+	if got := parseCgroupContainerID(strings.NewReader("0::/\n")); got != "" {
+		t.Fatalf("expected no id, got %q", got)
+	}
+	mi := "1234 567 0:45 /var/lib/docker/containers/" + testContainerID + "/resolv.conf /etc/resolv.conf rw - ext4 /dev/sda1 rw\n"
+	if got := parseMountinfoContainerID(strings.NewReader(mi)); got != testContainerID {
+		t.Fatalf("mountinfo parse = %q", got)
+	}
+}
+
+func TestHostEnricher(t *testing.T) {
+	e := newTestEvent()
+	e.Metadata = map[string]any{MetaHostName: "preset"}
+	if keep, err := HostEnricher().Process(context.Background(), &e); !keep || err != nil {
+		t.Fatalf("keep=%v err=%v", keep, err)
+	}
+	if e.Metadata[MetaHostName] != "preset" {
+		t.Fatalf("existing key overwritten")
+	}
+	if e.Metadata[MetaHostPID] != os.Getpid() || e.Metadata[MetaHostGoVersion] != runtime.Version() || e.Metadata[MetaHostSDKVersion] != Version {
+		t.Fatalf("unexpected host metadata %v", e.Metadata)
+	}
+}
+
+func TestKubernetesEnricher(t *testing.T) {
+	t.Setenv("POD_NAME", "api-7d9f")
+	t.Setenv("K8S_NAMESPACE", "agents")
+	t.Setenv("NODE_NAME", "node-1")
+	p := KubernetesEnricher()
+	e := newTestEvent()
+	_, _ = p.Process(context.Background(), &e)
+	if e.Metadata[MetaK8sPod] != "api-7d9f" || e.Metadata[MetaK8sNamespace] != "agents" || e.Metadata[MetaK8sNode] != "node-1" {
+		t.Fatalf("unexpected k8s metadata %v", e.Metadata)
+	}
+	if _, ok := e.Metadata[MetaK8sPodUID]; ok {
+		t.Fatalf("unset variable should be omitted")
+	}
+}
@@ -3,6 +3,7 @@ package packtrack
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // IngestError is a typed error for ingestion failures.
@@ -34,3 +35,79 @@ func JoinIngestError(e *IngestError, err error) *IngestError {
 	e.Cause = errors.Join(e.Cause, err)
 	return e
 }
+
+// FieldError describes a single invalid field of an Event.
+type FieldError struct {
+	Field   string // JSON path of the field, e.g. "workflow.id"
+	Message string
+}
+
+func (f FieldError) Error() string { return f.Field + ": " + f.Message }
+
+// ValidationError lists every field problem found on an Event.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil || len(e.Fields) == 0 {
+		return "invalid event"
+	}
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Error()
+	}
+	return "invalid event: " + strings.Join(parts, "; ")
+}
+
+// Has reports whether the given field path failed validation.
+func (e *ValidationError) Has(field string) bool {
+	if e == nil {
+		return false
+	}
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchValidationError reports which events of a batch failed validation.
+type BatchValidationError struct {
+	Indexes []int              // positions of invalid events, ascending
+	Errors  []*ValidationError // Errors[i] describes the event at Indexes[i]
+}
+
+func (e *BatchValidationError) Error() string {
+	if e == nil || len(e.Indexes) == 0 {
+		return "invalid batch"
+	}
+	parts := make([]string, len(e.Indexes))
+	for i, idx := range e.Indexes {
+		parts[i] = fmt.Sprintf("[%d] %v", idx, e.Errors[i])
+	}
+	return fmt.Sprintf("invalid batch: %d of the events failed validation: %s", len(e.Indexes), strings.Join(parts, ", "))
+}
+
+// add records an invalid event, allocating the error on first use.
+func (e *BatchValidationError) add(index int, ve *ValidationError) *BatchValidationError {
+	if e == nil {
+		e = &BatchValidationError{}
+	}
+	e.Indexes = append(e.Indexes, index)
+	e.Errors = append(e.Errors, ve)
+	return e
+}
+
+// Unwrap exposes the per-event errors for errors.Is/As.
+func (e *BatchValidationError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+	errs := make([]error, len(e.Errors))
+	for i, ve := range e.Errors {
+		errs[i] = ve
+	}
+	return errs
+}
@@ -1,19 +1,12 @@
 package packtrack
 
 import (
+	"bytes"
+	"encoding/json"
+	"sort"
 	"time"
 )
 
-// Severity is the event severity level.
-type Severity string
-
-const (
-	SeverityDebug Severity = "debug"
-	SeverityInfo  Severity = "info"
-	SeverityWarn  Severity = "warn"
-	SeverityError Severity = "error"
-)
-
 // Status represents high-level execution status for an event.
 type Status string
 
@@ -55,4 +48,93 @@ type Event struct {
 	Message   string         `json:"message"`
 	Metadata  map[string]any `json:"metadata,omitempty"`
 	Extra     map[string]any `json:"extra,omitempty"` // future-proof extensions
+
+	// Unknown holds top-level fields this SDK version does not recognize.
+	// They are populated by UnmarshalJSON and re-emitted by MarshalJSON, so
+	// events from newer schema versions can be relayed losslessly. It also
+	// keeps the original "severity" text when that decodes to SeverityUnknown.
+	Unknown map[string]json.RawMessage `json:"-"`
+}
+
+// eventAlias has Event's fields without its JSON methods.
+type eventAlias Event
+
+// knownEventFields are the top-level JSON keys owned by Event's struct fields.
+var knownEventFields = map[string]bool{
+	"timestamp": true, "source": true, "workflow": true, "actor": true,
+	"severity": true, "status": true, "message": true, "metadata": true, "extra": true,
+}
+
+// MarshalJSON encodes the event with its timestamp as RFC3339Nano in UTC,
+// followed by any Unknown fields in key order. Unknown keys that collide with
+// known fields are ignored, except that a SeverityUnknown severity is written
+// back as the original text kept in Unknown["severity"].
+func (e Event) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(struct {
+		Timestamp string `json:"timestamp"`
+		eventAlias
+	}{
+		Timestamp:  e.Timestamp.UTC().Format(time.RFC3339Nano),
+		eventAlias: eventAlias(e),
+	})
+	if err != nil || len(e.Unknown) == 0 {
+		return b, err
+	}
+	if raw, ok := e.Unknown["severity"]; ok && e.Severity == SeverityUnknown {
+		// Source, workflow and actor hold only strings, whose quotes are
+		// escaped, so the first match is the top-level severity.
+		var sev bytes.Buffer
+		if err := json.Compact(&sev, raw); err != nil {
+			return nil, err
+		}
+		b = bytes.Replace(b, []byte(`"severity":"unknown"`), append([]byte(`"severity":`), sev.Bytes()...), 1)
+	}
+
+	keys := make([]string, 0, len(e.Unknown))
+	for k := range e.Unknown {
+		if !knownEventFields[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1]) // drop closing brace
+	for _, k := range keys {
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(kb)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, e.Unknown[k]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the known fields and keeps every other top-level
+// field in Unknown, along with the original text of an unrecognized severity.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var a eventAlias
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for k, v := range raw {
+		if knownEventFields[k] && (k != "severity" || a.Severity != SeverityUnknown) {
+			continue
+		}
+		if a.Unknown == nil {
+			a.Unknown = make(map[string]json.RawMessage)
+		}
+		a.Unknown[k] = v
+	}
+	*e = Event(a)
+	return nil
 }
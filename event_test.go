@@ -0,0 +1,62 @@
+package packtrack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventJSON_RoundTripUnknownFields(t *testing.T) {
+	in := `{"timestamp":"2025-01-02T03:04:05.123456789Z","source":{"system":"s"},"workflow":{"id":"wf"},` +
+		`"actor":{"type":"agent","id":"a"},"severity":"info","status":"success","message":"m",` +
+		`"trace":{"id":"abc","sampled":true},"schema_version":2}`
+	var e Event
+	if err := json.Unmarshal([]byte(in), &e); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Unknown) != 2 || string(e.Unknown["schema_version"]) != "2" {
+		t.Fatalf("unexpected unknown fields %v", e.Unknown)
+	}
+	out, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got map[string]any
+	_ = json.Unmarshal([]byte(in), &want)
+	_ = json.Unmarshal(out, &got)
+	wb, _ := json.Marshal(want)
+	gb, _ := json.Marshal(got)
+	if string(wb) != string(gb) {
+		t.Fatalf("round trip mismatch\nwant %s\ngot  %s", wb, gb)
+	}
+}
+
+func TestEventJSON_TimestampUTCNano(t *testing.T) {
+	loc := time.FixedZone("X", 2*3600)
+	e := newTestEvent()
+	e.Timestamp = time.Date(2025, 1, 2, 5, 4, 5, 120, loc)
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"timestamp":"2025-01-02T03:04:05.00000012Z"`) {
+		t.Fatalf("unexpected timestamp encoding: %s", b)
+	}
+}
+
+func TestEventJSON_UnknownCannotShadowKnown(t *testing.T) {
+	e := newTestEvent()
+	e.Unknown = map[string]json.RawMessage{"message": json.RawMessage(`"evil"`)}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Event
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Message != "ok" || len(back.Unknown) != 0 {
+		t.Fatalf("unknown field shadowed known field: %s", b)
+	}
+}
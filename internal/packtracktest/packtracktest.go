@@ -0,0 +1,72 @@
+// Package packtracktest provides test doubles for the packtrack clients.
+package packtracktest
+
+import (
+	"context"
+	"sync"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// AsyncClient is an in-memory packtrack.AsyncClient that records enqueued
+// events. The zero value is ready to use and safe for concurrent use.
+type AsyncClient struct {
+	mu      sync.Mutex
+	events  []packtrack.Event
+	flushes int
+	err     error
+}
+
+var _ packtrack.AsyncClient = (*AsyncClient)(nil)
+
+// Enqueue records e, or returns the error set with SetErr.
+func (f *AsyncClient) Enqueue(e packtrack.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+// Flush counts the call.
+func (f *AsyncClient) Flush(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return nil
+}
+
+func (f *AsyncClient) Close(context.Context) error { return nil }
+
+// SetErr makes later Enqueue calls fail with err; nil restores success.
+func (f *AsyncClient) SetErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Events returns a copy of the recorded events.
+func (f *AsyncClient) Events() []packtrack.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]packtrack.Event(nil), f.events...)
+}
+
+// Last returns the most recently recorded event, or the zero Event.
+func (f *AsyncClient) Last() packtrack.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.events) == 0 {
+		return packtrack.Event{}
+	}
+	return f.events[len(f.events)-1]
+}
+
+// Flushes returns the number of Flush calls.
+func (f *AsyncClient) Flushes() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushes
+}
@@ -0,0 +1,307 @@
+package packtrack
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Metadata keys set on lifecycle events emitted by Run and Step.
+const (
+	MetaLifecycle    = "lifecycle"      // "run.start", "run.end", "step.start", "step.end"
+	MetaDurationMS   = "duration_ms"    // on end events
+	MetaError        = "error"          // error text on failed end events
+	MetaErrorType    = "error_type"     // Go type of the error on failed end events
+	MetaStepName     = "step_name"      // on step events
+	MetaParentStepID = "parent_step_id" // on nested step events and nested run events; a step's own parent wins
+
+	// On the events of a run started within another run's context, or under
+	// a workflow propagated from another service.
+	MetaParentRunID      = "parent_run_id"
+	MetaParentWorkflowID = "parent_workflow_id"
+)
+
+// Run is a handle for one workflow run, created by StartRun. It emits a
+// StatusRunning event when started and a success or error event from End.
+// A nil *Run is a valid no-op handle.
+type Run struct {
+	emit   Emitter
+	ctx    context.Context
+	wf     Workflow
+	parent map[string]any // parent links copied into lifecycle metadata
+	start  time.Time
+
+	mu       sync.Mutex
+	ended    bool
+	startErr error
+}
+
+// Step is a handle for one step of a Run, created by Run.StartStep,
+// Step.StartStep, or StartStep. A nil *Step is a valid no-op handle.
+type Step struct {
+	run    *Run
+	parent *Step
+	ctx    context.Context
+	id     string
+	name   string
+	start  time.Time
+
+	mu       sync.Mutex
+	ended    bool
+	startErr error
+}
+
+type runCtxKey struct{}
+type stepCtxKey struct{}
+
+// Emitter delivers the lifecycle events of a Run. ClientEmitter and
+// AsyncEmitter adapt the SDK clients.
+type Emitter func(ctx context.Context, e Event) error
+
+// ClientEmitter returns an Emitter that ingests each event with c.
+func ClientEmitter(c Client) Emitter {
+	return func(ctx context.Context, e Event) error {
+		_, err := c.IngestEvent(ctx, e)
+		return err
+	}
+}
+
+// AsyncEmitter returns an Emitter that enqueues each event on a.
+func AsyncEmitter(a AsyncClient) Emitter {
+	return func(_ context.Context, e Event) error { return a.Enqueue(e) }
+}
+
+// StartRun emits a StatusRunning event for wf through emit and returns a
+// handle whose End emits the outcome. Empty wf.ID and wf.Name are filled from
+// the context workflow, and wf.RunID is generated when empty. When ctx
+// carries a workflow with a RunID, whether from an enclosing Run or one
+// extracted from incoming headers, the new run's events link to it via
+// MetaParentRunID, MetaParentWorkflowID, and MetaParentStepID, on run and
+// step events alike. The run's contexts derive from ctx, but cancellation of
+// ctx does not stop later emissions.
+func StartRun(ctx context.Context, emit Emitter, wf Workflow) *Run {
+	var parent map[string]any
+	if up, ok := WorkflowFromContext(ctx); ok {
+		if wf.ID == "" || wf.ID == up.ID {
+			wf.ID = up.ID
+			if wf.Name == "" {
+				wf.Name = up.Name
+			}
+		}
+		if up.RunID != "" {
+			parent = map[string]any{MetaParentRunID: up.RunID, MetaParentWorkflowID: up.ID}
+			if up.StepID != "" {
+				parent[MetaParentStepID] = up.StepID
+			}
+		}
+	}
+	if wf.RunID == "" {
+		wf.RunID = newID(16)
+	}
+	wf.StepID = ""
+	r := &Run{emit: emit, wf: wf, parent: parent, start: time.Now()}
+	ctx = context.WithValue(ctx, runCtxKey{}, r)
+	ctx = context.WithValue(ctx, stepCtxKey{}, (*Step)(nil)) // hide any enclosing run's step
+	r.ctx = ContextWithWorkflow(ctx, wf)
+	r.startErr = r.send(Event{
+		Severity: SeverityInfo,
+		Status:   StatusRunning,
+		Message:  "run started: " + r.label(),
+		Metadata: map[string]any{MetaLifecycle: "run.start"},
+	})
+	return r
+}
+
+// Context returns a context carrying the run and its workflow, for passing to
+// code that starts nested steps via StartStep, ingests events, or calls Inject.
+func (r *Run) Context() context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// Workflow returns the run's workflow, including its generated RunID.
+func (r *Run) Workflow() Workflow {
+	if r == nil {
+		return Workflow{}
+	}
+	return r.wf
+}
+
+// StartStep starts a top-level step of the run.
+func (r *Run) StartStep(name string) *Step {
+	if r == nil {
+		return nil
+	}
+	return r.startStep(r.ctx, nil, name)
+}
+
+// End emits the run's final event: StatusSuccess when err is nil, otherwise
+// StatusError with the error details. Only the first call emits. The returned
+// error reports failures to emit the run's start or end events.
+func (r *Run) End(err error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	if r.ended {
+		r.mu.Unlock()
+		return nil
+	}
+	r.ended = true
+	startErr := r.startErr
+	r.mu.Unlock()
+	e := endEvent("run", r.label(), time.Since(r.start), err)
+	e.Metadata[MetaLifecycle] = "run.end"
+	return errors.Join(startErr, r.send(e))
+}
+
+func (r *Run) label() string {
+	if r.wf.Name != "" {
+		return r.wf.Name
+	}
+	return r.wf.ID
+}
+
+func (r *Run) send(e Event) error {
+	e.Timestamp = time.Now().UTC()
+	e.Workflow = r.wf
+	maps.Copy(e.Metadata, r.parent)
+	return r.emit(context.WithoutCancel(r.ctx), e)
+}
+
+func (r *Run) startStep(parentCtx context.Context, parent *Step, name string) *Step {
+	s := &Step{run: r, parent: parent, id: newID(8), name: name, start: time.Now()}
+	wf := r.wf
+	wf.StepID = s.id
+	s.ctx = ContextWithWorkflow(context.WithValue(parentCtx, stepCtxKey{}, s), wf)
+	md := map[string]any{MetaLifecycle: "step.start", MetaStepName: name}
+	if parent != nil {
+		md[MetaParentStepID] = parent.id
+	}
+	s.startErr = s.send(Event{
+		Severity: SeverityInfo,
+		Status:   StatusRunning,
+		Message:  "step started: " + name,
+		Metadata: md,
+	})
+	return s
+}
+
+// StartStep starts a step nested under s.
+func (s *Step) StartStep(name string) *Step {
+	if s == nil {
+		return nil
+	}
+	return s.run.startStep(s.ctx, s, name)
+}
+
+// Context returns a context carrying the step, its run, and its workflow
+// with StepID set.
+func (s *Step) Context() context.Context {
+	if s == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// ID returns the generated step ID.
+func (s *Step) ID() string {
+	if s == nil {
+		return ""
+	}
+	return s.id
+}
+
+// Run returns the run the step belongs to.
+func (s *Step) Run() *Run {
+	if s == nil {
+		return nil
+	}
+	return s.run
+}
+
+// End emits the step's final event, like Run.End.
+func (s *Step) End(err error) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return nil
+	}
+	s.ended = true
+	startErr := s.startErr
+	s.mu.Unlock()
+	e := endEvent("step", s.name, time.Since(s.start), err)
+	e.Metadata[MetaLifecycle] = "step.end"
+	e.Metadata[MetaStepName] = s.name
+	if s.parent != nil {
+		e.Metadata[MetaParentStepID] = s.parent.id
+	}
+	return errors.Join(startErr, s.send(e))
+}
+
+func (s *Step) send(e Event) error {
+	e.Timestamp = time.Now().UTC()
+	e.Workflow = s.run.wf
+	e.Workflow.StepID = s.id
+	for k, v := range s.run.parent {
+		if _, ok := e.Metadata[k]; !ok {
+			e.Metadata[k] = v
+		}
+	}
+	return s.run.emit(context.WithoutCancel(s.ctx), e)
+}
+
+// RunFromContext returns the Run carried by ctx, or nil.
+func RunFromContext(ctx context.Context) *Run {
+	r, _ := ctx.Value(runCtxKey{}).(*Run)
+	return r
+}
+
+// StepFromContext returns the innermost Step carried by ctx, or nil.
+func StepFromContext(ctx context.Context) *Step {
+	s, _ := ctx.Value(stepCtxKey{}).(*Step)
+	return s
+}
+
+// StartStep starts a step under the innermost Step or Run carried by ctx.
+// It returns nil (a no-op handle) when ctx carries neither.
+func StartStep(ctx context.Context, name string) *Step {
+	if s := StepFromContext(ctx); s != nil {
+		return s.StartStep(name)
+	}
+	return RunFromContext(ctx).StartStep(name)
+}
+
+func endEvent(kind, label string, d time.Duration, err error) Event {
+	e := Event{
+		Severity: SeverityInfo,
+		Status:   StatusSuccess,
+		Message:  kind + " completed: " + label,
+		Metadata: map[string]any{MetaDurationMS: d.Milliseconds()},
+	}
+	if err != nil {
+		e.Severity = SeverityError
+		e.Status = StatusError
+		e.Message = kind + " failed: " + label + ": " + err.Error()
+		e.Metadata[MetaError] = err.Error()
+		e.Metadata[MetaErrorType] = fmt.Sprintf("%T", err)
+	}
+	return e
+}
+
+// newID returns n random bytes, hex-encoded.
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,148 @@
+package packtrack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func newCaptureServer(t *testing.T) (*httptest.Server, func() []Event) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&raw)
+		var evs []Event
+		if len(raw) > 0 && raw[0] == '[' {
+			_ = json.Unmarshal(raw, &evs)
+		} else {
+			var e Event
+			_ = json.Unmarshal(raw, &e)
+			evs = []Event{e}
+		}
+		mu.Lock()
+		got = append(got, evs...)
+		mu.Unlock()
+		w.WriteHeader(200)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, func() []Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Event(nil), got...)
+	}
+}
+
+func TestRunAndSteps(t *testing.T) {
+	ts, events := newCaptureServer(t)
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithStrictValidation(),
+		WithDefaultSource(Source{System: "svc"}), WithDefaultActor(Actor{Type: "agent", ID: "a1"}))
+
+	run := StartRun(context.Background(), ClientEmitter(c), Workflow{ID: "wf", Name: "ingest"})
+	if run.Workflow().RunID == "" {
+		t.Fatalf("expected generated run id")
+	}
+	step := run.StartStep("fetch")
+	child := StartStep(step.Context(), "parse")
+	if child.Run() != run || RunFromContext(child.Context()) != run {
+		t.Fatalf("child step not linked to run")
+	}
+	if err := child.End(errors.New("bad json")); err != nil {
+		t.Fatal(err)
+	}
+	_ = child.End(nil) // second End is a no-op
+	_ = step.End(nil)
+	if err := run.End(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := events()
+	if len(got) != 6 {
+		t.Fatalf("expected 6 events, got %d", len(got))
+	}
+	for _, e := range got {
+		if e.Workflow.RunID != run.Workflow().RunID {
+			t.Fatalf("event not stamped with run id: %+v", e.Workflow)
+		}
+	}
+	if got[0].Status != StatusRunning || got[0].Workflow.StepID != "" {
+		t.Fatalf("unexpected run start %+v", got[0])
+	}
+	childEnd := got[3]
+	if childEnd.Status != StatusError || childEnd.Severity != SeverityError ||
+		childEnd.Metadata[MetaError] != "bad json" || childEnd.Metadata[MetaParentStepID] != step.ID() ||
+		childEnd.Workflow.StepID != child.ID() {
+		t.Fatalf("unexpected child end %+v", childEnd)
+	}
+	if _, ok := childEnd.Metadata[MetaDurationMS]; !ok {
+		t.Fatalf("missing duration")
+	}
+	if got[5].Status != StatusSuccess || got[5].Metadata[MetaLifecycle] != "run.end" {
+		t.Fatalf("unexpected run end %+v", got[5])
+	}
+}
+
+func TestNestedRun(t *testing.T) {
+	var got []Event
+	emit := func(_ context.Context, e Event) error { got = append(got, e); return nil }
+
+	outer := StartRun(context.Background(), emit, Workflow{ID: "wf"})
+	step := outer.StartStep("delegate")
+	inner := StartRun(step.Context(), emit, Workflow{ID: "sub-wf"})
+	if inner.Workflow().RunID == outer.Workflow().RunID || inner.Workflow().StepID != "" {
+		t.Fatalf("nested run reused parent ids: %+v", inner.Workflow())
+	}
+	if s := StartStep(inner.Context(), "work"); s.Run() != inner {
+		t.Fatalf("step in nested run attached to the enclosing run")
+	}
+	_ = inner.End(nil)
+
+	for _, e := range got[2:] {
+		if e.Metadata[MetaParentRunID] != outer.Workflow().RunID || e.Metadata[MetaParentWorkflowID] != "wf" ||
+			e.Metadata[MetaParentStepID] != step.ID() {
+			t.Fatalf("missing parent links on %v", e.Metadata)
+		}
+	}
+	if _, ok := got[0].Metadata[MetaParentRunID]; ok {
+		t.Fatalf("top-level run has a parent link")
+	}
+}
+
+func TestRunContextKeepsCancellation(t *testing.T) {
+	var got []Event
+	emit := func(ctx context.Context, e Event) error {
+		if ctx.Err() != nil {
+			t.Errorf("emitted with a canceled context")
+		}
+		got = append(got, e)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	run := StartRun(ctx, emit, Workflow{ID: "wf"})
+	step := run.StartStep("s")
+	cancel()
+	if run.Context().Err() == nil || step.Context().Err() == nil {
+		t.Fatalf("run and step contexts should follow the caller's cancellation")
+	}
+	_ = step.End(nil)
+	_ = run.End(nil)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 events after cancellation, got %d", len(got))
+	}
+}
+
+func TestRunNilSafe(t *testing.T) {
+	s := StartStep(context.Background(), "orphan")
+	if s != nil || s.End(nil) != nil || s.StartStep("x") != nil {
+		t.Fatalf("expected no-op step")
+	}
+	var r *Run
+	if r.End(nil) != nil || r.StartStep("x") != nil {
+		t.Fatalf("expected no-op run")
+	}
+}
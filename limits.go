@@ -0,0 +1,252 @@
+package packtrack
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"unicode/utf8"
+)
+
+// TruncatedKey is the Metadata key listing what size limits cut from an
+// event. Each entry is an object with "field", "reason", and "original_size".
+// The same key marks values replaced by a truncation marker:
+//
+//	{"_truncated": true, "original_size": 52311}
+const TruncatedKey = "_truncated"
+
+// Truncation reasons recorded in Metadata[TruncatedKey].
+const (
+	TruncatedMessageLength = "message_length"
+	TruncatedDepth         = "depth"
+	TruncatedKeyCount      = "key_count"
+	TruncatedEventSize     = "event_size"
+)
+
+// SizeLimits bounds event size. Zero fields are unlimited. Original sizes are
+// bytes of JSON encoding, except for key_count where it is the number of keys.
+type SizeLimits struct {
+	// MaxMessageBytes truncates Message, including an appended marker with
+	// the original length, to at most this many bytes.
+	MaxMessageBytes int
+	// MaxMetadataDepth replaces containers nested deeper than this (the
+	// Metadata map itself is depth 1) with a truncation marker.
+	MaxMetadataDepth int
+	// MaxMetadataKeys keeps the first keys in sorted order of each Metadata map.
+	MaxMetadataKeys int
+	// MaxEventBytes replaces the largest top-level Metadata, Extra, and
+	// unknown values with markers, then shortens Message, until the encoded event fits.
+	MaxEventBytes int
+}
+
+func (l SizeLimits) enabled() bool {
+	return l.MaxMessageBytes > 0 || l.MaxMetadataDepth > 0 || l.MaxMetadataKeys > 0 || l.MaxEventBytes > 0
+}
+
+// enforce applies the limits to e and returns the number of truncations.
+// Containers are copied, never mutated in place.
+func (l SizeLimits) enforce(e *Event) int {
+	var notes []any
+	note := func(field, reason string, size int) {
+		notes = append(notes, map[string]any{"field": field, "reason": reason, "original_size": size})
+	}
+
+	orig := e.Message
+	if l.MaxMessageBytes > 0 && len(e.Message) > l.MaxMessageBytes {
+		n := len(e.Message)
+		e.Message = truncateMessage(e.Message, l.MaxMessageBytes, n)
+		note("message", TruncatedMessageLength, n)
+	}
+	if e.Metadata != nil && (l.MaxMetadataDepth > 0 || l.MaxMetadataKeys > 0) {
+		e.Metadata = l.limitMap(e.Metadata, "metadata", 1, note)
+	}
+	if l.MaxEventBytes > 0 {
+		l.fitEvent(e, orig, &notes, note)
+	}
+	if len(notes) > 0 {
+		e.Metadata = withTruncationNotes(e.Metadata, notes)
+	}
+	return len(notes)
+}
+
+func (l SizeLimits) limitMap(m map[string]any, path string, depth int, note func(string, string, int)) map[string]any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if l.MaxMetadataKeys > 0 && len(keys) > l.MaxMetadataKeys {
+		note(path, TruncatedKeyCount, len(keys))
+		kept := keys[:0:0]
+		for _, k := range keys {
+			if len(kept) < l.MaxMetadataKeys || reservedMetadataKey(k) {
+				kept = append(kept, k)
+			}
+		}
+		keys = kept
+	}
+	out := make(map[string]any, len(keys))
+	for _, k := range keys {
+		out[k] = l.limitValue(m[k], path+"."+k, depth, note)
+	}
+	return out
+}
+
+// limitValue limits v, which lives inside a container at the given depth.
+func (l SizeLimits) limitValue(v any, path string, depth int, note func(string, string, int)) any {
+	switch t := v.(type) {
+	case map[string]any, []any, map[string]string, []string:
+		if l.MaxMetadataDepth > 0 && depth+1 > l.MaxMetadataDepth {
+			size := encodedSize(t)
+			note(path, TruncatedDepth, size)
+			return truncationMarker(size)
+		}
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		return l.limitMap(t, path, depth+1, note)
+	case []any:
+		out := make([]any, len(t))
+		for i, x := range t {
+			out[i] = l.limitValue(x, fmt.Sprintf("%s[%d]", path, i), depth+1, note)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// fitEvent cuts the largest top-level Metadata, Extra, and Unknown values,
+// then the message, until the encoded event fits MaxEventBytes.
+func (l SizeLimits) fitEvent(e *Event, origMessage string, notes *[]any, note func(string, string, int)) {
+	size := encodedEventSize(*e, *notes)
+	if size <= l.MaxEventBytes {
+		return
+	}
+
+	type candidate struct {
+		field string // "metadata", "extra", or "" for an unknown top-level field
+		key   string
+		size  int
+	}
+	const minCut = 64 // smaller values would not shrink once replaced by a marker
+	var cands []candidate
+	for k, v := range e.Metadata {
+		if s := encodedSize(v); s > minCut && !reservedMetadataKey(k) {
+			cands = append(cands, candidate{field: "metadata", key: k, size: s})
+		}
+	}
+	for k, v := range e.Extra {
+		if s := encodedSize(v); s > minCut {
+			cands = append(cands, candidate{field: "extra", key: k, size: s})
+		}
+	}
+	for k, v := range e.Unknown {
+		if s := len(v); s > minCut {
+			cands = append(cands, candidate{key: k, size: s})
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].size != cands[j].size {
+			return cands[i].size > cands[j].size
+		}
+		if cands[i].field != cands[j].field {
+			return cands[i].field < cands[j].field
+		}
+		return cands[i].key < cands[j].key
+	})
+
+	metaCopied, extraCopied, unknownCopied := false, false, false
+	for _, c := range cands {
+		if size <= l.MaxEventBytes {
+			return
+		}
+		switch c.field {
+		case "extra":
+			if !extraCopied {
+				e.Extra, extraCopied = maps.Clone(e.Extra), true
+			}
+			e.Extra[c.key] = truncationMarker(c.size)
+			note("extra."+c.key, TruncatedEventSize, c.size)
+		case "metadata":
+			if !metaCopied {
+				e.Metadata, metaCopied = maps.Clone(e.Metadata), true
+			}
+			e.Metadata[c.key] = truncationMarker(c.size)
+			note("metadata."+c.key, TruncatedEventSize, c.size)
+		default:
+			if !unknownCopied {
+				e.Unknown, unknownCopied = maps.Clone(e.Unknown), true
+			}
+			e.Unknown[c.key], _ = json.Marshal(truncationMarker(c.size))
+			note(c.key, TruncatedEventSize, c.size)
+		}
+		size = encodedEventSize(*e, *notes)
+	}
+	if size <= l.MaxEventBytes || e.Message == "" {
+		return
+	}
+	n := len(origMessage)
+	note("message", TruncatedEventSize, n)
+	size = encodedEventSize(*e, *notes)
+	budget := min(n, len(e.Message))
+	// JSON escaping can make the encoded message longer than its bytes, so
+	// shrink until it fits.
+	for size > l.MaxEventBytes && budget > 0 {
+		budget = max(budget-(size-l.MaxEventBytes), 0)
+		e.Message = truncateMessage(origMessage, budget, n)
+		size = encodedEventSize(*e, *notes)
+	}
+}
+
+func reservedMetadataKey(k string) bool { return k == TruncatedKey || k == SampleRateKey }
+
+func truncationMarker(size int) map[string]any {
+	return map[string]any{TruncatedKey: true, "original_size": size}
+}
+
+func withTruncationNotes(md map[string]any, notes []any) map[string]any {
+	md = maps.Clone(md)
+	if md == nil {
+		md = make(map[string]any, 1)
+	}
+	md[TruncatedKey] = notes
+	return md
+}
+
+func encodedSize(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+func encodedEventSize(e Event, notes []any) int {
+	if len(notes) > 0 {
+		e.Metadata = withTruncationNotes(e.Metadata, notes)
+	}
+	return encodedSize(e)
+}
+
+func messageMarker(original int) string {
+	return fmt.Sprintf("…[truncated, %d bytes]", original)
+}
+
+// truncateMessage cuts s on a rune boundary and appends a marker with the
+// original length, so that the result is at most n bytes. When n cannot hold
+// the marker, s is cut to n bytes without one.
+func truncateMessage(s string, n, original int) string {
+	marker := messageMarker(original)
+	keep := n - len(marker)
+	if keep < 0 {
+		keep, marker = n, ""
+	}
+	if keep < len(s) {
+		for keep > 0 && !utf8.RuneStart(s[keep]) {
+			keep--
+		}
+		s = s[:keep]
+	}
+	return s + marker
+}
@@ -0,0 +1,97 @@
+package packtrack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func truncationNotes(t *testing.T, e Event) []map[string]any {
+	t.Helper()
+	raw, ok := e.Metadata[TruncatedKey].([]any)
+	if !ok {
+		t.Fatalf("missing %s note in %v", TruncatedKey, e.Metadata)
+	}
+	var out []map[string]any
+	for _, n := range raw {
+		out = append(out, n.(map[string]any))
+	}
+	return out
+}
+
+func TestSizeLimits_MessageAndDepth(t *testing.T) {
+	l := SizeLimits{MaxMessageBytes: 40, MaxMetadataDepth: 2, MaxMetadataKeys: 3}
+	e := newTestEvent()
+	e.Message = strings.Repeat("é", 40) // 80 bytes
+	inner := map[string]any{"deep": map[string]any{"x": 1}}
+	e.Metadata = map[string]any{"a": 1, "b": inner, "c": 3, "d": 4}
+	if n := l.enforce(&e); n != 3 {
+		t.Fatalf("expected 3 truncations, got %d", n)
+	}
+	if e.Message != strings.Repeat("é", 8)+"…[truncated, 80 bytes]" || len(e.Message) > 40 {
+		t.Fatalf("unexpected message %q", e.Message)
+	}
+	if _, ok := e.Metadata["d"]; ok {
+		t.Fatalf("expected key d to be dropped")
+	}
+	marker := e.Metadata["b"].(map[string]any)["deep"].(map[string]any)
+	if marker[TruncatedKey] != true || marker["original_size"] != 7 {
+		t.Fatalf("unexpected depth marker %v", marker)
+	}
+	if _, ok := inner["deep"].(map[string]any)["x"]; !ok {
+		t.Fatalf("caller map mutated")
+	}
+	notes := truncationNotes(t, e)
+	if notes[0]["field"] != "message" || notes[1]["reason"] != TruncatedKeyCount || notes[2]["field"] != "metadata.b.deep" {
+		t.Fatalf("unexpected notes %v", notes)
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	for _, n := range []int{0, 5, 24, 25, 30} {
+		got := truncateMessage(strings.Repeat("é", 20), n, 40)
+		if len(got) > n || !utf8.ValidString(got) {
+			t.Errorf("truncateMessage(_, %d) = %q (%d bytes)", n, got, len(got))
+		}
+	}
+}
+
+func TestSizeLimits_EventBytesUnknown(t *testing.T) {
+	var e Event
+	big := strings.Repeat("y", 3000)
+	if err := json.Unmarshal([]byte(`{"message":"m","future":"`+big+`"}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	raw := e.Unknown["future"]
+	SizeLimits{MaxEventBytes: 512}.enforce(&e)
+	b, _ := json.Marshal(e)
+	if len(b) > 512 || e.Message != "m" {
+		t.Fatalf("event %d bytes: %s", len(b), b)
+	}
+	if notes := truncationNotes(t, e); len(notes) != 1 || notes[0]["field"] != "future" {
+		t.Fatalf("unexpected notes %v", notes)
+	}
+	if len(raw) != len(big)+2 {
+		t.Fatalf("caller's Unknown value was mutated")
+	}
+}
+
+func TestSizeLimits_EventBytes(t *testing.T) {
+	l := SizeLimits{MaxEventBytes: 1024}
+	e := newTestEvent()
+	e.Metadata = map[string]any{"transcript": strings.Repeat("x", 5000), "small": "keep"}
+	e.Message = strings.Repeat(`"q"`, 400)
+	l.enforce(&e)
+	b, _ := json.Marshal(e)
+	if len(b) > 1024 {
+		t.Fatalf("event still %d bytes", len(b))
+	}
+	if e.Metadata["small"] != "keep" {
+		t.Fatalf("small value should survive")
+	}
+	notes := truncationNotes(t, e)
+	if len(notes) != 2 || notes[0]["field"] != "metadata.transcript" || notes[1]["field"] != "message" {
+		t.Fatalf("unexpected notes %v", notes)
+	}
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// Metadata keys set by the PackTrack-backed Logger.
+const (
+	MetaTraceID = "trace_id"
+	MetaSpanID  = "span_id"
+	MetaFatal   = "fatal"
+)
+
+// Severity maps l to a packtrack.Severity. LevelFatal maps to error; unknown
+// levels map to info.
+func (l Level) Severity() packtrack.Severity {
+	switch l {
+	case LevelDebug:
+		return packtrack.SeverityDebug
+	case LevelWarn:
+		return packtrack.SeverityWarn
+	case LevelError, LevelFatal:
+		return packtrack.SeverityError
+	default:
+		return packtrack.SeverityInfo
+	}
+}
+
+// Option configures a Logger returned by New.
+type Option func(*config)
+
+type config struct {
+	minLevel     Level
+	fatalTimeout time.Duration
+	onFatal      func(Event)
+}
+
+// WithMinLevel discards events below l. Defaults to LevelDebug.
+func WithMinLevel(l Level) Option { return func(c *config) { c.minLevel = l } }
+
+// WithFatalFlushTimeout bounds the flush performed after a LevelFatal event.
+// Defaults to 5s.
+func WithFatalFlushTimeout(d time.Duration) Option {
+	return func(c *config) { c.fatalTimeout = d }
+}
+
+// WithFatalHandler calls fn after a LevelFatal event has been flushed, e.g.
+// func(Event) { os.Exit(1) }. By default the Logger does not exit.
+func WithFatalHandler(fn func(Event)) Option { return func(c *config) { c.onFatal = fn } }
+
+type logger struct {
+	client packtrack.AsyncClient
+	cfg    *config
+	fields Fields
+}
+
+// New returns a Logger that enqueues events on client. Logger fields and
+// event fields are merged into Metadata (event fields win), TraceID and
+// SpanID are recorded under MetaTraceID and MetaSpanID, and the workflow
+// carried by the Log context is attached. A LevelFatal event is logged at
+// error severity and flushed before Log returns.
+func New(client packtrack.AsyncClient, opts ...Option) Logger {
+	cfg := &config{minLevel: LevelDebug, fatalTimeout: 5 * time.Second}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return &logger{client: client, cfg: cfg}
+}
+
+func (l *logger) Log(ctx context.Context, ev Event) error {
+	if ev.Level < l.cfg.minLevel {
+		return nil
+	}
+	e := packtrack.Event{
+		Severity: ev.Level.Severity(),
+		Status:   packtrack.StatusSuccess,
+		Message:  ev.Message,
+		Metadata: make(map[string]any, len(l.fields)+len(ev.Fields)+2),
+	}
+	if !ev.Time.IsZero() {
+		e.Timestamp = ev.Time.UTC()
+	}
+	if e.Severity == packtrack.SeverityError {
+		e.Status = packtrack.StatusError
+	}
+	if wf, ok := packtrack.WorkflowFromContext(ctx); ok {
+		e.Workflow = wf
+	}
+	maps.Copy(e.Metadata, l.fields)
+	maps.Copy(e.Metadata, ev.Fields)
+	if ev.TraceID != "" {
+		e.Metadata[MetaTraceID] = ev.TraceID
+	}
+	if ev.SpanID != "" {
+		e.Metadata[MetaSpanID] = ev.SpanID
+	}
+	if ev.Level != LevelFatal {
+		return l.client.Enqueue(e)
+	}
+
+	e.Metadata[MetaFatal] = true
+	err := l.client.Enqueue(e)
+	fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.cfg.fatalTimeout)
+	defer cancel()
+	err = errors.Join(err, l.client.Flush(fctx))
+	if l.cfg.onFatal != nil {
+		l.cfg.onFatal(ev)
+	}
+	return err
+}
+
+// With returns a child logger; the parent and fields are not modified.
+func (l *logger) With(fields Fields) Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	maps.Copy(merged, l.fields)
+	maps.Copy(merged, fields)
+	return &logger{client: l.client, cfg: l.cfg, fields: merged}
+}
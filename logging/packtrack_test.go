@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func TestLogger(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	base := New(fake, WithMinLevel(LevelInfo))
+	child := base.With(Fields{"service": "billing", "region": "eu"})
+	grandchild := child.With(Fields{"region": "us"})
+
+	ctx := packtrack.ContextWithWorkflow(context.Background(), packtrack.Workflow{ID: "wf-1", RunID: "run-1"})
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("X", 3600))
+	if err := grandchild.Log(ctx, Event{
+		Time: ts, Level: LevelWarn, Message: "slow", Fields: Fields{"service": "api"},
+		TraceID: "t1", SpanID: "s1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_ = child.Log(context.Background(), Event{Level: LevelInfo, Message: "child"})
+	_ = base.Log(context.Background(), Event{Level: LevelDebug, Message: "dropped"})
+
+	events := fake.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	e := events[0]
+	if e.Severity != packtrack.SeverityWarn || e.Status != packtrack.StatusSuccess || !e.Timestamp.Equal(ts) || e.Timestamp.Location() != time.UTC {
+		t.Errorf("event = %+v", e)
+	}
+	if e.Workflow.ID != "wf-1" || e.Workflow.RunID != "run-1" {
+		t.Errorf("workflow = %+v", e.Workflow)
+	}
+	md := e.Metadata
+	if md["service"] != "api" || md["region"] != "us" || md[MetaTraceID] != "t1" || md[MetaSpanID] != "s1" {
+		t.Errorf("metadata = %v", md)
+	}
+	if got := events[1].Metadata; got["region"] != "eu" || got["service"] != "billing" {
+		t.Errorf("child metadata changed by grandchild: %v", got)
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	var fatal []Event
+	l := New(fake, WithFatalHandler(func(ev Event) { fatal = append(fatal, ev) }))
+	if err := l.Log(context.Background(), Event{Level: LevelFatal, Message: "out of disk"}); err != nil {
+		t.Fatal(err)
+	}
+	e := fake.Events()[0]
+	if e.Severity != packtrack.SeverityError || e.Status != packtrack.StatusError || e.Metadata[MetaFatal] != true {
+		t.Errorf("event = %+v", e)
+	}
+	if fake.Flushes() != 1 || len(fatal) != 1 {
+		t.Errorf("flushed = %d, fatal handler calls = %d", fake.Flushes(), len(fatal))
+	}
+}
+
+func TestLoggerFatalDelivered(t *testing.T) {
+	var mu sync.Mutex
+	var got []packtrack.Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []packtrack.Event
+		_ = json.NewDecoder(r.Body).Decode(&batch)
+		mu.Lock()
+		got = append(got, batch...)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+	c, _ := packtrack.NewClient(packtrack.WithBaseURL(ts.URL), packtrack.WithAPIKey("k"))
+	ac, _ := packtrack.NewAsyncClient(c, packtrack.WithBatchSize(100), packtrack.WithFlushInterval(time.Hour))
+	defer ac.Close(context.Background())
+
+	delivered := -1
+	l := New(ac, WithFatalHandler(func(Event) {
+		mu.Lock()
+		delivered = len(got)
+		mu.Unlock()
+	}))
+	_ = l.Log(context.Background(), Event{Level: LevelInfo, Message: "before"})
+	time.Sleep(20 * time.Millisecond) // let the worker take the event into its batch
+	if err := l.Log(context.Background(), Event{Level: LevelFatal, Message: "out of disk"}); err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 2 {
+		t.Fatalf("events delivered before the fatal handler = %d, want 2", delivered)
+	}
+}
+
+func TestLevelSeverity(t *testing.T) {
+	for l, want := range map[Level]packtrack.Severity{
+		LevelDebug: packtrack.SeverityDebug,
+		LevelInfo:  packtrack.SeverityInfo,
+		LevelWarn:  packtrack.SeverityWarn,
+		LevelError: packtrack.SeverityError,
+		LevelFatal: packtrack.SeverityError,
+		Level(42):  packtrack.SeverityInfo,
+	} {
+		if got := l.Severity(); got != want {
+			t.Errorf("%d.Severity() = %v, want %v", l, got, want)
+		}
+	}
+}
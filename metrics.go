@@ -1,8 +1,138 @@
 package packtrack
 
-// MetricsHooks provides optional callbacks for observability.
+import "time"
+
+// Drop reasons reported through MetricsHooks.OnDropped.
+const (
+	DropReasonSeverity  = "severity"  // below the configured minimum severity
+	DropReasonSampled   = "sampled"   // not selected by the Sampler
+	DropReasonProcessor = "processor" // an EventProcessor returned keep=false
+
+	DropReasonOverflowOldest = "overflow_oldest" // evicted from a full queue by OverflowDropOldest
+	DropReasonOverflowNewest = "overflow_newest" // not queued under OverflowDropNewest
+)
+
+// Retry reasons reported through MetricsHooks.OnRetry.
+const (
+	RetryReasonNetwork     = "network"      // the request failed before a response
+	RetryReasonRateLimited = "rate_limited" // 429 Too Many Requests
+	RetryReasonServerError = "server_error" // 5xx
+)
+
+// Enqueue rejection reasons reported through MetricsHooks.OnEnqueueRejected.
+const (
+	RejectReasonQueueFull = "queue_full" // the queue was at capacity
+	RejectReasonClosed    = "closed"     // the AsyncClient was closed
+	RejectReasonInvalid   = "invalid"    // strict validation failed at Enqueue
+	RejectReasonCanceled  = "canceled"   // the EnqueueContext context was done while blocked
+)
+
+// MetricsHooks provides optional callbacks for observability. Hooks are
+// called synchronously from the goroutine doing the work, never while the
+// SDK holds an internal lock, so they may call back into the client, for
+// example to Enqueue; they should still return quickly. Hooks may run on an
+// AsyncClient worker, which AsyncClient.Flush and Close wait for, so they
+// must not call those two methods.
 type MetricsHooks struct {
+	// OnIngestSuccess and OnIngestFailure report the number of events in
+	// each ingest request that succeeded or finally failed.
 	OnIngestSuccess func(count int)
 	OnIngestFailure func(count int)
-	OnQueueDepth    func(depth int)
+	// OnQueueDepth reports the AsyncClient queue length after each enqueue
+	// and after each batch is taken off the queue.
+	OnQueueDepth func(depth int)
+	// OnEnqueued reports events accepted by AsyncClient.Enqueue.
+	OnEnqueued func(count int)
+	// OnEnqueueRejected reports events AsyncClient.Enqueue returned an error for.
+	OnEnqueueRejected func(reason string, count int)
+	// OnFlush reports each batch the AsyncClient sends, whether from a full
+	// batch, the flush interval, Flush, or Close, with the time it took.
+	OnFlush func(duration time.Duration, count int)
+	// OnRetry reports an ingest request about to be retried; attempt is the
+	// 1-based number of the retry.
+	OnRetry func(attempt int, reason string)
+	// OnRequest reports each ingest HTTP attempt. statusCode is 0 when the
+	// request failed without a response.
+	OnRequest func(latency time.Duration, statusCode int)
+	// OnPayload reports the size of each ingest payload before and after
+	// compression; both are equal when compression is off.
+	OnPayload func(uncompressed, compressed int)
+	// OnDropped reports events intentionally discarded client-side.
+	OnDropped func(reason string, count int)
+	// OnTruncated reports how many fields size limits cut from one event.
+	OnTruncated func(count int)
+	// OnProcessorError reports an EventProcessor that failed or panicked.
+	OnProcessorError func(err error)
+}
+
+func (h *MetricsHooks) dropped(reason string, count int) {
+	if h != nil && h.OnDropped != nil && count > 0 {
+		h.OnDropped(reason, count)
+	}
+}
+
+func (h *MetricsHooks) truncated(count int) {
+	if h != nil && h.OnTruncated != nil && count > 0 {
+		h.OnTruncated(count)
+	}
+}
+
+func (h *MetricsHooks) processorError(err error) {
+	if h != nil && h.OnProcessorError != nil {
+		h.OnProcessorError(err)
+	}
+}
+
+func (h *MetricsHooks) ingestSuccess(count int) {
+	if h != nil && h.OnIngestSuccess != nil {
+		h.OnIngestSuccess(count)
+	}
+}
+
+func (h *MetricsHooks) ingestFailure(count int) {
+	if h != nil && h.OnIngestFailure != nil {
+		h.OnIngestFailure(count)
+	}
+}
+
+func (h *MetricsHooks) queueDepth(depth int) {
+	if h != nil && h.OnQueueDepth != nil {
+		h.OnQueueDepth(depth)
+	}
+}
+
+func (h *MetricsHooks) enqueued(count int) {
+	if h != nil && h.OnEnqueued != nil {
+		h.OnEnqueued(count)
+	}
+}
+
+func (h *MetricsHooks) retry(attempt int, reason string) {
+	if h != nil && h.OnRetry != nil {
+		h.OnRetry(attempt, reason)
+	}
+}
+
+func (h *MetricsHooks) request(latency time.Duration, statusCode int) {
+	if h != nil && h.OnRequest != nil {
+		h.OnRequest(latency, statusCode)
+	}
+}
+
+func (h *MetricsHooks) payload(uncompressed, compressed int) {
+	if h != nil && h.OnPayload != nil {
+		h.OnPayload(uncompressed, compressed)
+	}
+}
+
+func (h *MetricsHooks) enqueueRejected(reason string, count int) {
+	if h != nil && h.OnEnqueueRejected != nil {
+		h.OnEnqueueRejected(reason, count)
+	}
+}
+
+func (h *MetricsHooks) flush(d time.Duration, count int) {
+	if h != nil && h.OnFlush != nil {
+		h.OnFlush(d, count)
+	}
 }
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/logging"
+)
+
+// FieldRepeatCount is set by Dedupe on the first entry let through after a
+// window in which duplicates were suppressed, and on the summary entry passed
+// on when such a key is evicted.
+const FieldRepeatCount = "repeat_count"
+
+// now is replaced in tests.
+var now = time.Now
+
+// Chain composes middlewares so that the first is outermost:
+// Chain(a, b)(h) calls a, then b, then h.
+func Chain(mws ...LogMiddleware) LogMiddleware {
+	return func(next LogHandler) LogHandler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i] != nil {
+				next = mws[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// InjectFields adds fields to every entry. Fields already set on the entry win.
+func InjectFields(fields map[string]any) LogMiddleware {
+	return InjectFunc(func(context.Context) map[string]any { return fields })
+}
+
+// InjectFunc adds the fields returned by fn, e.g. request-scoped values from
+// the context. Fields already set on the entry win.
+func InjectFunc(fn func(ctx context.Context) map[string]any) LogMiddleware {
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, level int, message string, fields map[string]any) error {
+			extra := fn(ctx)
+			if len(extra) == 0 {
+				return next(ctx, level, message, fields)
+			}
+			merged := make(map[string]any, len(extra)+len(fields))
+			maps.Copy(merged, extra)
+			maps.Copy(merged, fields)
+			return next(ctx, level, message, merged)
+		}
+	}
+}
+
+// MinLevel drops entries below level. Like the other log middlewares, it
+// interprets LogHandler levels as logging.Level values.
+func MinLevel(level logging.Level) LogMiddleware {
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, l int, message string, fields map[string]any) error {
+			if logging.Level(l) < level {
+				return nil
+			}
+			return next(ctx, l, message, fields)
+		}
+	}
+}
+
+// RateLimit lets at most n entries with the same message through per interval
+// and drops the rest.
+func RateLimit(n int, interval time.Duration) LogMiddleware {
+	type window struct {
+		start time.Time
+		count int
+	}
+	var (
+		mu        sync.Mutex
+		windows   = make(map[string]*window)
+		lastPrune time.Time
+	)
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, level int, message string, fields map[string]any) error {
+			t := now()
+			mu.Lock()
+			if t.Sub(lastPrune) >= interval {
+				for k, w := range windows {
+					if t.Sub(w.start) >= interval {
+						delete(windows, k)
+					}
+				}
+				lastPrune = t
+			}
+			w := windows[message]
+			if w == nil || t.Sub(w.start) >= interval {
+				w = &window{start: t}
+				windows[message] = w
+			}
+			w.count++
+			allowed := w.count <= n
+			mu.Unlock()
+			if !allowed {
+				return nil
+			}
+			return next(ctx, level, message, fields)
+		}
+	}
+}
+
+// Dedupe drops entries identical in level and message to one let through
+// within the preceding window. The next entry let through for that key
+// carries the number suppressed in FieldRepeatCount. Keys not seen again
+// are evicted once their window has passed; if they had suppressed entries,
+// the last of those is passed on first, with the count, so none go
+// unreported.
+func Dedupe(window time.Duration) LogMiddleware {
+	var (
+		mu        sync.Mutex
+		entries   = make(map[string]*dedupeEntry)
+		lastPrune time.Time
+	)
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, level int, message string, fields map[string]any) error {
+			key := strconv.Itoa(level) + "\x00" + message
+			t := now()
+			var evicted []*dedupeEntry
+			mu.Lock()
+			if t.Sub(lastPrune) >= window {
+				for k, s := range entries {
+					if k != key && t.Sub(s.at) >= window {
+						delete(entries, k)
+						if s.suppressed > 0 {
+							evicted = append(evicted, s)
+						}
+					}
+				}
+				lastPrune = t
+			}
+			s := entries[key]
+			if s != nil && t.Sub(s.at) < window {
+				s.suppressed++
+				s.ctx, s.fields = context.WithoutCancel(ctx), fields
+				mu.Unlock()
+				return flushEvicted(next, evicted)
+			}
+			repeated := 0
+			if s != nil {
+				repeated = s.suppressed
+			}
+			entries[key] = &dedupeEntry{at: t, level: level, message: message}
+			mu.Unlock()
+			err := flushEvicted(next, evicted)
+			if repeated > 0 {
+				fields = withRepeatCount(fields, repeated)
+			}
+			return errors.Join(err, next(ctx, level, message, fields))
+		}
+	}
+}
+
+// dedupeEntry tracks one Dedupe key. ctx and fields are those of the last
+// suppressed entry, passed on if the key is evicted.
+type dedupeEntry struct {
+	at         time.Time
+	level      int
+	message    string
+	suppressed int
+	ctx        context.Context
+	fields     map[string]any
+}
+
+// flushEvicted passes on the last suppressed entry of each evicted Dedupe
+// key, with its suppressed count.
+func flushEvicted(next LogHandler, evicted []*dedupeEntry) error {
+	var errs []error
+	for _, s := range evicted {
+		errs = append(errs, next(s.ctx, s.level, s.message, withRepeatCount(s.fields, s.suppressed)))
+	}
+	return errors.Join(errs...)
+}
+
+func withRepeatCount(fields map[string]any, n int) map[string]any {
+	fields = maps.Clone(fields)
+	if fields == nil {
+		fields = make(map[string]any, 1)
+	}
+	fields[FieldRepeatCount] = n
+	return fields
+}
+
+// Sample lets a random fraction of entries through. Entries at
+// logging.LevelError and above are always kept.
+func Sample(rate float64) LogMiddleware {
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, level int, message string, fields map[string]any) error {
+			if logging.Level(level) < logging.LevelError && rand.Float64() >= rate {
+				return nil
+			}
+			return next(ctx, level, message, fields)
+		}
+	}
+}
+
+// Recover turns a panic in the rest of the chain into an error.
+func Recover() LogMiddleware {
+	return func(next LogHandler) LogHandler {
+		return func(ctx context.Context, level int, message string, fields map[string]any) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("log handler panicked: %v", p)
+				}
+			}()
+			return next(ctx, level, message, fields)
+		}
+	}
+}
+
+// AsyncLogHandler returns a terminal LogHandler that enqueues entries on
+// client. Fields become Metadata and the workflow carried by ctx is attached.
+func AsyncLogHandler(client packtrack.AsyncClient) LogHandler {
+	return func(ctx context.Context, level int, message string, fields map[string]any) error {
+		sev := logging.Level(level).Severity()
+		e := packtrack.Event{
+			Timestamp: now().UTC(),
+			Severity:  sev,
+			Status:    packtrack.StatusSuccess,
+			Message:   message,
+			Metadata:  maps.Clone(fields),
+		}
+		if sev == packtrack.SeverityError {
+			e.Status = packtrack.StatusError
+		}
+		if wf, ok := packtrack.WorkflowFromContext(ctx); ok {
+			e.Workflow = wf
+		}
+		return client.Enqueue(e)
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+	"github.com/commandant-labs/pack-track-sdk/logging"
+)
+
+type entry struct {
+	level   int
+	message string
+	fields  map[string]any
+}
+
+type sink struct{ entries []entry }
+
+func (s *sink) handle(_ context.Context, level int, message string, fields map[string]any) error {
+	s.entries = append(s.entries, entry{level, message, fields})
+	return nil
+}
+
+// fakeClock replaces now for the duration of a test.
+func fakeClock(t *testing.T) *time.Time {
+	t.Helper()
+	c := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return c }
+	t.Cleanup(func() { now = time.Now })
+	return &c
+}
+
+func TestChainOrderAndInject(t *testing.T) {
+	var order []string
+	mark := func(name string) LogMiddleware {
+		return func(next LogHandler) LogHandler {
+			return func(ctx context.Context, l int, m string, f map[string]any) error {
+				order = append(order, name)
+				return next(ctx, l, m, f)
+			}
+		}
+	}
+	s := &sink{}
+	static := map[string]any{"service": "billing", "env": "prod"}
+	h := Chain(mark("a"), InjectFields(static), mark("b"), MinLevel(logging.LevelInfo))(s.handle)
+
+	caller := map[string]any{"env": "dev"}
+	_ = h(context.Background(), int(logging.LevelInfo), "hello", caller)
+	_ = h(context.Background(), int(logging.LevelDebug), "filtered", nil)
+
+	if strings.Join(order, ",") != "a,b,a,b" {
+		t.Errorf("order = %v", order)
+	}
+	if len(s.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(s.entries))
+	}
+	f := s.entries[0].fields
+	if f["service"] != "billing" || f["env"] != "dev" {
+		t.Errorf("fields = %v", f)
+	}
+	if len(caller) != 1 || len(static) != 2 {
+		t.Error("input maps were mutated")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := fakeClock(t)
+	s := &sink{}
+	h := RateLimit(2, time.Second)(s.handle)
+	for range 5 {
+		_ = h(context.Background(), 1, "retrying", nil)
+	}
+	_ = h(context.Background(), 1, "other", nil)
+	*clock = clock.Add(time.Second)
+	_ = h(context.Background(), 1, "retrying", nil)
+
+	if len(s.entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(s.entries))
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	clock := fakeClock(t)
+	s := &sink{}
+	h := Dedupe(time.Minute)(s.handle)
+	for range 3 {
+		_ = h(context.Background(), 3, "disk full", nil)
+	}
+	_ = h(context.Background(), 2, "disk full", nil) // different level
+	*clock = clock.Add(time.Minute)
+	_ = h(context.Background(), 3, "disk full", map[string]any{"disk": "/data"})
+
+	if len(s.entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(s.entries))
+	}
+	if got := s.entries[2].fields; got[FieldRepeatCount] != 2 || got["disk"] != "/data" {
+		t.Errorf("fields = %v", got)
+	}
+}
+
+func TestDedupeEvictsSuppressed(t *testing.T) {
+	clock := fakeClock(t)
+	s := &sink{}
+	h := Dedupe(time.Minute)(s.handle)
+	_ = h(context.Background(), 3, "disk full", nil)
+	_ = h(context.Background(), 3, "disk full", map[string]any{"disk": "/a"})
+	_ = h(context.Background(), 3, "disk full", map[string]any{"disk": "/b"})
+	*clock = clock.Add(time.Minute)
+	_ = h(context.Background(), 2, "other", nil) // prunes "disk full"
+
+	if len(s.entries) != 3 {
+		t.Fatalf("got %d entries, want 3: %+v", len(s.entries), s.entries)
+	}
+	if e := s.entries[1]; e.message != "disk full" || e.fields[FieldRepeatCount] != 2 || e.fields["disk"] != "/b" {
+		t.Errorf("summary = %+v", e)
+	}
+	if s.entries[2].message != "other" {
+		t.Errorf("entries = %+v", s.entries)
+	}
+
+	*clock = clock.Add(time.Minute)
+	_ = h(context.Background(), 3, "disk full", nil)
+	if got := s.entries[3].fields; got[FieldRepeatCount] != nil {
+		t.Errorf("count reported twice: %v", got)
+	}
+}
+
+func TestSampleKeepsErrors(t *testing.T) {
+	s := &sink{}
+	h := Sample(0)(s.handle)
+	_ = h(context.Background(), int(logging.LevelInfo), "dropped", nil)
+	_ = h(context.Background(), int(logging.LevelError), "kept", nil)
+	if len(s.entries) != 1 || s.entries[0].message != "kept" {
+		t.Errorf("entries = %+v", s.entries)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := Recover()(func(context.Context, int, string, map[string]any) error { panic("boom") })
+	if err := h(context.Background(), 1, "x", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestAsyncLogHandler(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	h := Chain(InjectFields(map[string]any{"service": "billing"}))(AsyncLogHandler(fake))
+	ctx := packtrack.ContextWithWorkflow(context.Background(), packtrack.Workflow{ID: "wf-1"})
+	if err := h(ctx, int(logging.LevelError), "charge failed", nil); err != nil {
+		t.Fatal(err)
+	}
+	e := fake.Events()[0]
+	if e.Severity != packtrack.SeverityError || e.Status != packtrack.StatusError ||
+		e.Workflow.ID != "wf-1" || e.Metadata["service"] != "billing" || e.Timestamp.IsZero() {
+		t.Errorf("event = %+v", e)
+	}
+}
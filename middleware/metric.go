@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/commandant-labs/pack-track-sdk/telemetry"
+)
+
+// OtherLabelValue replaces label values beyond a CardinalityLimit.
+const OtherLabelValue = "__other__"
+
+// ErrInvalidMetricName is returned by NamingRules for names with no usable
+// characters.
+var ErrInvalidMetricName = errors.New("invalid metric name")
+
+// ChainMetrics composes metric middlewares so that the first is outermost,
+// like Chain.
+func ChainMetrics(mws ...MetricMiddleware) MetricMiddleware {
+	return func(next MetricHandler) MetricHandler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			if mws[i] != nil {
+				next = mws[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// CardinalityLimit allows at most n distinct values per label key of each
+// metric name. Further values are recorded as OtherLabelValue.
+func CardinalityLimit(n int) MetricMiddleware {
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]map[string]map[string]struct{}) // name → key → values
+	)
+	return func(next MetricHandler) MetricHandler {
+		return func(ctx context.Context, name string, value float64, labels map[string]string) error {
+			var out map[string]string
+			mu.Lock()
+			keys := seen[name]
+			if keys == nil {
+				keys = make(map[string]map[string]struct{})
+				seen[name] = keys
+			}
+			for k, v := range labels {
+				vals := keys[k]
+				if vals == nil {
+					vals = make(map[string]struct{})
+					keys[k] = vals
+				}
+				if _, ok := vals[v]; ok {
+					continue
+				}
+				if len(vals) < n {
+					vals[v] = struct{}{}
+					continue
+				}
+				if out == nil {
+					out = maps.Clone(labels)
+				}
+				out[k] = OtherLabelValue
+			}
+			mu.Unlock()
+			if out == nil {
+				out = labels
+			}
+			return next(ctx, name, value, out)
+		}
+	}
+}
+
+// DenyLabels drops the given label keys, e.g. unbounded identifiers such as
+// user_id.
+func DenyLabels(keys ...string) MetricMiddleware {
+	deny := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		deny[k] = true
+	}
+	return func(next MetricHandler) MetricHandler {
+		return func(ctx context.Context, name string, value float64, labels map[string]string) error {
+			var out map[string]string
+			for k := range labels {
+				if deny[k] {
+					if out == nil {
+						out = maps.Clone(labels)
+					}
+					delete(out, k)
+				}
+			}
+			if out == nil {
+				out = labels
+			}
+			return next(ctx, name, value, out)
+		}
+	}
+}
+
+// NamingRules rewrites metric names and label keys to lower snake_case using
+// only [a-z0-9_], and prepends prefix (e.g. "myapp_") to names lacking it.
+// Names left empty are rejected with ErrInvalidMetricName.
+func NamingRules(prefix string) MetricMiddleware {
+	return func(next MetricHandler) MetricHandler {
+		return func(ctx context.Context, name string, value float64, labels map[string]string) error {
+			n := sanitizeName(name)
+			if n == "" {
+				return ErrInvalidMetricName
+			}
+			if prefix != "" && !strings.HasPrefix(n, prefix) {
+				n = prefix + n
+			}
+			var out map[string]string
+			for k, v := range labels {
+				if sk := sanitizeName(k); sk != k {
+					if out == nil {
+						out = maps.Clone(labels)
+					}
+					delete(out, k)
+					if sk != "" {
+						out[sk] = v
+					}
+				}
+			}
+			if out == nil {
+				out = labels
+			}
+			return next(ctx, n, value, out)
+		}
+	}
+}
+
+// sanitizeName lowercases s, splits camelCase, replaces other characters with
+// underscores, and collapses and trims underscores. A leading digit gets an
+// underscore prefix.
+func sanitizeName(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	underscore := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z':
+			if i > 0 && s[i-1] >= 'a' && s[i-1] <= 'z' {
+				underscore()
+			}
+			b.WriteByte(c + 'a' - 'A')
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteByte(c)
+		default:
+			underscore()
+		}
+	}
+	out := strings.TrimRight(b.String(), "_")
+	if out != "" && out[0] >= '0' && out[0] <= '9' {
+		out = "_" + out
+	}
+	return out
+}
+
+// unitSuffixes maps name suffixes to a base unit suffix and the factor
+// converting a value into it.
+var unitSuffixes = []struct {
+	suffix, base string
+	factor       float64
+}{
+	{"_nanoseconds", "_seconds", 1e-9},
+	{"_ns", "_seconds", 1e-9},
+	{"_microseconds", "_seconds", 1e-6},
+	{"_us", "_seconds", 1e-6},
+	{"_milliseconds", "_seconds", 1e-3},
+	{"_ms", "_seconds", 1e-3},
+	{"_secs", "_seconds", 1},
+	{"_sec", "_seconds", 1},
+	{"_s", "_seconds", 1},
+	{"_minutes", "_seconds", 60},
+	{"_kib", "_bytes", 1 << 10},
+	{"_mib", "_bytes", 1 << 20},
+	{"_gib", "_bytes", 1 << 30},
+	{"_kb", "_bytes", 1e3},
+	{"_mb", "_bytes", 1e6},
+	{"_gb", "_bytes", 1e9},
+}
+
+// NormalizeUnits converts durations to seconds and sizes to bytes based on
+// the name's unit suffix, renaming e.g. latency_ms to latency_seconds and
+// upload_kib to upload_bytes. A trailing _total is preserved.
+func NormalizeUnits() MetricMiddleware {
+	return func(next MetricHandler) MetricHandler {
+		return func(ctx context.Context, name string, value float64, labels map[string]string) error {
+			n, v := normalizeUnit(name, value)
+			return next(ctx, n, v, labels)
+		}
+	}
+}
+
+func normalizeUnit(name string, value float64) (string, float64) {
+	stem, total := strings.CutSuffix(name, "_total")
+	for _, u := range unitSuffixes {
+		if s, ok := strings.CutSuffix(stem, u.suffix); ok && s != "" {
+			stem, value = s+u.base, value*u.factor
+			break
+		}
+	}
+	if total {
+		stem += "_total"
+	}
+	return stem, value
+}
+
+type metricTypeKey struct{}
+
+// ContextWithMetricType returns a context telling RecorderHandler the type of
+// the metric being recorded.
+func ContextWithMetricType(ctx context.Context, t telemetry.Type) context.Context {
+	return context.WithValue(ctx, metricTypeKey{}, t)
+}
+
+// RecorderHandler returns a terminal MetricHandler recording on r. The type
+// comes from ContextWithMetricType; otherwise names ending in _total are
+// counters and the rest gauges. Names ending in _seconds or _bytes get the
+// matching Unit.
+func RecorderHandler(r telemetry.Recorder) MetricHandler {
+	return func(ctx context.Context, name string, value float64, labels map[string]string) error {
+		typ, ok := ctx.Value(metricTypeKey{}).(telemetry.Type)
+		if !ok {
+			typ = telemetry.Gauge
+			if strings.HasSuffix(name, "_total") {
+				typ = telemetry.Counter
+			}
+		}
+		stem := strings.TrimSuffix(name, "_total")
+		unit := ""
+		switch {
+		case strings.HasSuffix(stem, "_seconds"):
+			unit = "seconds"
+		case strings.HasSuffix(stem, "_bytes"):
+			unit = "bytes"
+		}
+		return r.Record(ctx, telemetry.Metric{
+			Time:   now().UTC(),
+			Name:   name,
+			Type:   typ,
+			Value:  value,
+			Unit:   unit,
+			Labels: telemetry.Labels(maps.Clone(labels)),
+		})
+	}
+}
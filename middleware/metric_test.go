@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/commandant-labs/pack-track-sdk/telemetry"
+)
+
+type recorded struct {
+	name   string
+	value  float64
+	labels map[string]string
+}
+
+type metricSink struct{ got []recorded }
+
+func (s *metricSink) handle(_ context.Context, name string, value float64, labels map[string]string) error {
+	s.got = append(s.got, recorded{name, value, labels})
+	return nil
+}
+
+func TestCardinalityLimitAndDenyLabels(t *testing.T) {
+	s := &metricSink{}
+	h := ChainMetrics(DenyLabels("user_id"), CardinalityLimit(2))(s.handle)
+	for _, route := range []string{"/a", "/b", "/c", "/a", "/d"} {
+		labels := map[string]string{"route": route, "user_id": "u-" + route}
+		_ = h(context.Background(), "requests_total", 1, labels)
+		if labels["user_id"] == "" {
+			t.Fatal("caller labels were mutated")
+		}
+	}
+	_ = h(context.Background(), "errors_total", 1, map[string]string{"route": "/c"})
+
+	var routes []string
+	for _, r := range s.got {
+		if _, ok := r.labels["user_id"]; ok {
+			t.Errorf("denied label kept: %v", r.labels)
+		}
+		routes = append(routes, r.labels["route"])
+	}
+	want := []string{"/a", "/b", OtherLabelValue, "/a", OtherLabelValue, "/c"}
+	for i := range want {
+		if routes[i] != want[i] {
+			t.Fatalf("routes = %v, want %v", routes, want)
+		}
+	}
+}
+
+func TestNamingRules(t *testing.T) {
+	s := &metricSink{}
+	h := NamingRules("app_")(s.handle)
+	_ = h(context.Background(), "HTTP Request.LatencyMs", 1, map[string]string{"statusCode": "200", "ok": "y"})
+	if err := h(context.Background(), "--", 1, nil); !errors.Is(err, ErrInvalidMetricName) {
+		t.Errorf("err = %v", err)
+	}
+	_ = h(context.Background(), "app_up", 1, nil)
+
+	if s.got[0].name != "app_http_request_latency_ms" {
+		t.Errorf("name = %q", s.got[0].name)
+	}
+	if l := s.got[0].labels; l["status_code"] != "200" || l["ok"] != "y" || len(l) != 2 {
+		t.Errorf("labels = %v", l)
+	}
+	if s.got[1].name != "app_up" {
+		t.Errorf("prefixed twice: %q", s.got[1].name)
+	}
+}
+
+func TestNormalizeUnits(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		value float64
+		want  string
+		wantV float64
+	}{
+		{"latency_ms", 250, "latency_seconds", 0.25},
+		{"gc_pause_ns_total", 2e9, "gc_pause_seconds_total", 2},
+		{"upload_kib", 2, "upload_bytes", 2048},
+		{"upload_mb", 1.5, "upload_bytes", 1.5e6},
+		{"latency_seconds", 3, "latency_seconds", 3},
+		{"requests_total", 7, "requests_total", 7},
+	} {
+		n, v := normalizeUnit(tc.name, tc.value)
+		if n != tc.want || math.Abs(v-tc.wantV) > 1e-9 {
+			t.Errorf("normalizeUnit(%q, %v) = %q, %v; want %q, %v", tc.name, tc.value, n, v, tc.want, tc.wantV)
+		}
+	}
+}
+
+type fakeRecorder struct{ metrics []telemetry.Metric }
+
+func (r *fakeRecorder) Record(_ context.Context, m telemetry.Metric) error {
+	r.metrics = append(r.metrics, m)
+	return nil
+}
+
+func TestRecorderHandler(t *testing.T) {
+	rec := &fakeRecorder{}
+	h := ChainMetrics(NormalizeUnits())(RecorderHandler(rec))
+	_ = h(context.Background(), "requests_total", 1, map[string]string{"route": "/"})
+	_ = h(context.Background(), "latency_ms", 120, nil)
+	_ = h(ContextWithMetricType(context.Background(), telemetry.Histogram), "payload_kib", 1, nil)
+
+	m := rec.metrics
+	if m[0].Type != telemetry.Counter || m[0].Labels["route"] != "/" || m[0].Time.IsZero() {
+		t.Errorf("counter = %+v", m[0])
+	}
+	if m[1].Type != telemetry.Gauge || m[1].Unit != "seconds" || m[1].Name != "latency_seconds" {
+		t.Errorf("gauge = %+v", m[1])
+	}
+	if m[2].Type != telemetry.Histogram || m[2].Unit != "bytes" || m[2].Value != 1024 {
+		t.Errorf("histogram = %+v", m[2])
+	}
+}
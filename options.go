@@ -1,6 +1,7 @@
 package packtrack
 
 import (
+	"maps"
 	"net/http"
 	"time"
 )
@@ -43,6 +44,32 @@ type Config struct {
 	// Health check options
 	HealthPath   string
 	HealthEnable bool
+
+	// StrictValidation rejects events failing Event.Validate before sending.
+	StrictValidation bool
+
+	// Defaults merged into events that leave these fields empty.
+	DefaultSource   Source
+	DefaultActor    Actor
+	DefaultMetadata map[string]any
+
+	// Severity threshold. Events below the applicable minimum are dropped
+	// before sending. Workflow overrides take precedence over source overrides.
+	MinSeverity         Severity
+	SourceMinSeverity   map[string]Severity // keyed by Source.System
+	WorkflowMinSeverity map[string]Severity // keyed by Workflow.ID
+
+	// Optional run-consistent sampler applied after the severity threshold.
+	Sampler *Sampler
+
+	// Optional redactor applied to every kept event before it is queued or sent.
+	Redactor *Redactor
+
+	// Optional per-event size limits applied after redaction.
+	Limits SizeLimits
+
+	// Processors run in order after the sampler and before the redactor.
+	Processors []EventProcessor
 }
 
 // Option configures the Client via functional options.
@@ -53,7 +80,7 @@ func Defaults() Config {
 	return Config{
 		BaseURL:   "https://pack.shimcounty.com",
 		Timeout:   15 * time.Second,
-		UserAgent: "pack-track-sdk-go/0.1.0",
+		UserAgent: "pack-track-sdk-go/" + Version,
 		Retry: RetryConfig{
 			MaxAttempts:    3,
 			InitialBackoff: 100 * time.Millisecond,
@@ -97,3 +124,68 @@ func WithMetricsHooks(h *MetricsHooks) Option { return func(c *Config) { c.Metri
 func WithHealthEnabled(enabled bool) Option { return func(c *Config) { c.HealthEnable = enabled } }
 
 func WithHealthPath(p string) Option { return func(c *Config) { c.HealthPath = p } }
+
+// WithStrictValidation makes the client reject events that fail Event.Validate
+// instead of sending them. Batches are rejected as a whole with a
+// *BatchValidationError naming the offending indexes.
+func WithStrictValidation() Option { return func(c *Config) { c.StrictValidation = true } }
+
+// WithDefaultSource sets Source on events whose Source.System is empty. An
+// Env the event already sets is kept.
+func WithDefaultSource(src Source) Option { return func(c *Config) { c.DefaultSource = src } }
+
+// WithDefaultActor sets Actor on events whose Actor.ID is empty. A Type or
+// DisplayName the event already sets is kept.
+func WithDefaultActor(a Actor) Option { return func(c *Config) { c.DefaultActor = a } }
+
+// WithDefaultMetadata adds metadata keys that an event does not already set.
+// m is copied; later changes to it have no effect.
+func WithDefaultMetadata(m map[string]any) Option {
+	m = maps.Clone(m)
+	return func(c *Config) { c.DefaultMetadata = m }
+}
+
+// WithMinSeverity drops events below s. When used with NewAsyncClient the
+// check runs at Enqueue, so filtered events never occupy the queue.
+func WithMinSeverity(s Severity) Option { return func(c *Config) { c.MinSeverity = s } }
+
+// WithSourceMinSeverity overrides the minimum severity for one Source.System.
+func WithSourceMinSeverity(system string, s Severity) Option {
+	return func(c *Config) {
+		if c.SourceMinSeverity == nil {
+			c.SourceMinSeverity = make(map[string]Severity)
+		}
+		c.SourceMinSeverity[system] = s
+	}
+}
+
+// WithWorkflowMinSeverity overrides the minimum severity for one Workflow.ID.
+func WithWorkflowMinSeverity(workflowID string, s Severity) Option {
+	return func(c *Config) {
+		if c.WorkflowMinSeverity == nil {
+			c.WorkflowMinSeverity = make(map[string]Severity)
+		}
+		c.WorkflowMinSeverity[workflowID] = s
+	}
+}
+
+// WithSampler applies s to every event after the severity threshold.
+func WithSampler(s *Sampler) Option { return func(c *Config) { c.Sampler = s } }
+
+// WithRedactor scrubs every kept event with r before it is queued or sent.
+func WithRedactor(r *Redactor) Option { return func(c *Config) { c.Redactor = r } }
+
+// WithSizeLimits truncates oversized messages and metadata before events are
+// queued or sent. See SizeLimits.
+func WithSizeLimits(l SizeLimits) Option { return func(c *Config) { c.Limits = l } }
+
+// WithProcessors appends event processors to the pipeline. See EventProcessor.
+func WithProcessors(ps ...EventProcessor) Option {
+	return func(c *Config) {
+		for _, p := range ps {
+			if p != nil {
+				c.Processors = append(c.Processors, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package packtrackhttp instruments net/http servers and clients with
+// PackTrack events.
+package packtrackhttp
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// Metadata keys set on request events.
+const (
+	MetaMethod        = "http.method"
+	MetaRoute         = "http.route"
+	MetaPath          = "http.path"
+	MetaStatusCode    = "http.status_code"
+	MetaDurationMS    = "http.duration_ms"
+	MetaRequestBytes  = "http.request_bytes"
+	MetaResponseBytes = "http.response_bytes"
+	MetaRemoteAddr    = "http.remote_addr"
+	MetaUserAgent     = "http.user_agent"
+)
+
+// RequestInfo describes a completed request, for message templates.
+type RequestInfo struct {
+	Method     string
+	Route      string // ServeMux pattern; empty when no pattern matched
+	Path       string
+	Status     int
+	Duration   time.Duration
+	Bytes      int64
+	RemoteAddr string
+}
+
+// Option configures Middleware.
+type Option func(*config)
+
+type config struct {
+	skipPaths map[string]bool
+	skip      func(*http.Request) bool
+	rate      float64
+	message   func(RequestInfo) string
+	workflow  packtrack.Workflow
+}
+
+// WithSkipPaths skips requests whose URL path equals one of paths, such as
+// health or metrics endpoints.
+func WithSkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, p := range paths {
+			c.skipPaths[p] = true
+		}
+	}
+}
+
+// WithSkip skips requests for which fn returns true.
+func WithSkip(fn func(*http.Request) bool) Option { return func(c *config) { c.skip = fn } }
+
+// WithSampleRate keeps a random fraction of requests. Requests answered with
+// a 5xx status are always kept.
+func WithSampleRate(rate float64) Option { return func(c *config) { c.rate = rate } }
+
+// WithMessage sets the function producing each event's message.
+func WithMessage(fn func(RequestInfo) string) Option { return func(c *config) { c.message = fn } }
+
+// WithMessageTemplate renders each event's message from t executed with a
+// RequestInfo, e.g. template.Must(template.New("").Parse("{{.Method}} {{.Route}}")).
+// Execution errors fall back to the default message.
+func WithMessageTemplate(t *template.Template) Option {
+	return func(c *config) {
+		c.message = func(ri RequestInfo) string {
+			var b strings.Builder
+			if err := t.Execute(&b, ri); err != nil {
+				return defaultMessage(ri)
+			}
+			return b.String()
+		}
+	}
+}
+
+// WithDefaultWorkflow sets the workflow for requests that carry no workflow
+// headers.
+func WithDefaultWorkflow(wf packtrack.Workflow) Option {
+	return func(c *config) { c.workflow = wf }
+}
+
+// defaultMessage renders e.g. "GET /items/{id} 200", dropping the method
+// prefix of method-qualified ServeMux patterns. Requests without a route
+// show their path.
+func defaultMessage(ri RequestInfo) string {
+	route := ri.Route
+	if route == "" {
+		route = ri.Path
+	}
+	if i := strings.IndexByte(route, ' '); i >= 0 {
+		route = strings.TrimLeft(route[i+1:], " ")
+	}
+	return ri.Method + " " + route + " " + strconv.Itoa(ri.Status)
+}
+
+// Middleware returns HTTP middleware that enqueues one event per request on
+// client. The workflow context is extracted from incoming headers (see
+// packtrack.Extract) and made available to the handler via the request
+// context. Severity follows the status code: 5xx is error, 4xx is warn, and
+// everything else is info.
+func Middleware(client packtrack.AsyncClient, opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{skipPaths: make(map[string]bool), rate: 1, message: defaultMessage}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.skipPaths[r.URL.Path] || (cfg.skip != nil && cfg.skip(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := packtrack.Extract(r.Context(), packtrack.HeaderCarrier(r.Header))
+			r = r.WithContext(ctx)
+			rec := &responseRecorder{ResponseWriter: w}
+			start := time.Now()
+			defer func() {
+				if p := recover(); p != nil {
+					rec.status = http.StatusInternalServerError
+					cfg.emit(client, r, rec, time.Since(start))
+					panic(p)
+				}
+				cfg.emit(client, r, rec, time.Since(start))
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+func (c *config) emit(client packtrack.AsyncClient, r *http.Request, rec *responseRecorder, d time.Duration) {
+	status := rec.statusCode()
+	if status < 500 && c.rate < 1 && rand.Float64() >= c.rate {
+		return
+	}
+	ri := RequestInfo{
+		Method:     r.Method,
+		Route:      r.Pattern,
+		Path:       r.URL.Path,
+		Status:     status,
+		Duration:   d,
+		Bytes:      rec.bytes,
+		RemoteAddr: r.RemoteAddr,
+	}
+	wf, ok := packtrack.WorkflowFromContext(r.Context())
+	if !ok {
+		wf = c.workflow
+	}
+	e := packtrack.Event{
+		Timestamp: time.Now().UTC(),
+		Workflow:  wf,
+		Severity:  packtrack.SeverityInfo,
+		Status:    packtrack.StatusSuccess,
+		Message:   c.message(ri),
+		Metadata: map[string]any{
+			MetaMethod:        ri.Method,
+			MetaPath:          ri.Path,
+			MetaStatusCode:    status,
+			MetaDurationMS:    d.Milliseconds(),
+			MetaResponseBytes: rec.bytes,
+			MetaRemoteAddr:    ri.RemoteAddr,
+		},
+	}
+	// Raw paths are unbounded in cardinality, so the route is left out
+	// rather than falling back to one.
+	if ri.Route != "" {
+		e.Metadata[MetaRoute] = ri.Route
+	}
+	if r.ContentLength > 0 {
+		e.Metadata[MetaRequestBytes] = r.ContentLength
+	}
+	if ua := r.UserAgent(); ua != "" {
+		e.Metadata[MetaUserAgent] = ua
+	}
+	switch {
+	case status >= 500:
+		e.Severity, e.Status = packtrack.SeverityError, packtrack.StatusError
+	case status >= 400:
+		e.Severity = packtrack.SeverityWarn
+	}
+	_ = client.Enqueue(e) // rejections are reported through the client's MetricsHooks
+}
+
+// responseRecorder captures the status code and body size.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack forwards to the wrapped writer, so WebSocket and other upgrade
+// handlers keep working. A hijacked request is recorded as 101 Switching
+// Protocols unless a status was already written.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("packtrackhttp: %T does not implement http.Hijacker", r.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
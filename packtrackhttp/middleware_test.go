@@ -0,0 +1,161 @@
+package packtrackhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func TestMiddleware(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	var gotWF packtrack.Workflow
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		gotWF, _ = packtrack.WorkflowFromContext(r.Context())
+		io.WriteString(w, "hello")
+	})
+	mux.HandleFunc("POST /fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	})
+	h := Middleware(fake)(mux)
+
+	req := httptest.NewRequest("GET", "/items/42", nil)
+	req.Header.Set(packtrack.HeaderWorkflowID, "wf-1")
+	req.Header.Set(packtrack.HeaderRunID, "run-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/fail", strings.NewReader("x")))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	if gotWF.ID != "wf-1" || gotWF.RunID != "run-1" {
+		t.Fatalf("handler context workflow = %+v", gotWF)
+	}
+	events := fake.Events()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+
+	ok := events[0]
+	if ok.Workflow.ID != "wf-1" || ok.Workflow.RunID != "run-1" {
+		t.Errorf("workflow = %+v", ok.Workflow)
+	}
+	if ok.Severity != packtrack.SeverityInfo || ok.Status != packtrack.StatusSuccess {
+		t.Errorf("severity/status = %v/%v", ok.Severity, ok.Status)
+	}
+	md := ok.Metadata
+	if md[MetaMethod] != "GET" || md[MetaRoute] != "GET /items/{id}" || md[MetaPath] != "/items/42" ||
+		md[MetaStatusCode] != 200 || md[MetaResponseBytes] != int64(5) || md[MetaRemoteAddr] != req.RemoteAddr {
+		t.Errorf("metadata = %v", md)
+	}
+	if _, ok := md[MetaDurationMS]; !ok {
+		t.Error("missing duration")
+	}
+	if ok.Message != "GET /items/{id} 200" {
+		t.Errorf("message = %q", ok.Message)
+	}
+
+	fail := events[1]
+	if fail.Severity != packtrack.SeverityError || fail.Status != packtrack.StatusError || fail.Metadata[MetaStatusCode] != 502 {
+		t.Errorf("5xx event = %+v", fail)
+	}
+	if fail.Metadata[MetaRequestBytes] != int64(1) {
+		t.Errorf("request bytes = %v", fail.Metadata[MetaRequestBytes])
+	}
+	if _, ok := events[2].Metadata[MetaRoute]; ok || events[2].Severity != packtrack.SeverityWarn ||
+		events[2].Message != "GET /missing 404" {
+		t.Errorf("404 event = %+v", events[2])
+	}
+}
+
+func TestMiddlewareOptions(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	tmpl := template.Must(template.New("").Parse("{{.Method}} {{.Path}} -> {{.Status}}"))
+	h := Middleware(fake,
+		WithSkipPaths("/healthz"),
+		WithSkip(func(r *http.Request) bool { return r.Method == http.MethodOptions }),
+		WithSampleRate(0),
+		WithMessageTemplate(tmpl),
+		WithDefaultWorkflow(packtrack.Workflow{ID: "api"}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/err" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest("GET", "/healthz", nil),
+		httptest.NewRequest("OPTIONS", "/err", nil),
+		httptest.NewRequest("GET", "/ok", nil), // sampled out
+		httptest.NewRequest("GET", "/err", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	events := fake.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want only the 5xx", len(events))
+	}
+	if events[0].Message != "GET /err -> 500" || events[0].Workflow.ID != "api" {
+		t.Errorf("event = %+v", events[0])
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	h := Middleware(fake)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") }))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was swallowed")
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	if events := fake.Events(); len(events) != 1 || events[0].Metadata[MetaStatusCode] != 500 {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	ts := httptest.NewServer(Middleware(fake)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		rw.Flush()
+	})))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	// The server stops tracking a hijacked connection before the handler
+	// returns, so wait for the event.
+	var events []packtrack.Event
+	for deadline := time.Now().Add(2 * time.Second); len(events) == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+		events = fake.Events()
+	}
+	if len(events) != 1 || events[0].Metadata[MetaStatusCode] != http.StatusSwitchingProtocols {
+		t.Fatalf("events = %+v", events)
+	}
+
+	rec := &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Error("expected error from a writer that cannot hijack")
+	}
+}
@@ -0,0 +1,388 @@
+package packtrackhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/backoff"
+)
+
+// Metadata keys set on outbound request events, in addition to MetaMethod,
+// MetaPath, MetaStatusCode, and MetaDurationMS.
+const (
+	MetaHost         = "http.host"
+	MetaRetries      = "http.retries"
+	MetaErrorClass   = "http.error_class"
+	MetaRequestBody  = "http.request_body"
+	MetaResponseBody = "http.response_body"
+)
+
+// Error classes recorded in MetaErrorClass.
+const (
+	ErrorClassTimeout     = "timeout"
+	ErrorClassCanceled    = "canceled"
+	ErrorClassDNS         = "dns"
+	ErrorClassTLS         = "tls"
+	ErrorClassRefused     = "connection_refused"
+	ErrorClassReset       = "connection_reset"
+	ErrorClassNetwork     = "network"
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassClient      = "client_error"
+	ErrorClassServer      = "server_error"
+)
+
+// truncatedBodyMarker is appended to captured bodies cut at the capture limit.
+const truncatedBodyMarker = "…[truncated]"
+
+// TransportOption configures NewTransport.
+type TransportOption func(*transportConfig)
+
+type transportConfig struct {
+	retries     int
+	backoff     backoff.Exponential
+	captureMax  int
+	redactor    *packtrack.Redactor
+	skip        func(*http.Request) bool
+	noPropagate bool
+}
+
+// WithRetries retries requests up to n times on network errors and 429, 502,
+// 503, and 504 responses, waiting with exponential backoff between attempts.
+// Requests whose body cannot be replayed (GetBody is nil) are not retried.
+func WithRetries(n int, initial, maxBackoff time.Duration) TransportOption {
+	return func(c *transportConfig) {
+		c.retries = n
+		c.backoff = backoff.Exponential{Initial: initial, Max: maxBackoff, Jitter: 0.2}
+	}
+}
+
+// WithBodyCapture records up to maxBytes of each request and response body,
+// scrubbed with r. A nil r uses packtrack.DefaultRedactionConfig. Response
+// bodies are captured as the caller reads them, so the event is emitted when
+// the response body is closed or fully read. 101 Switching Protocols
+// responses are left unwrapped, since their body is the upgraded connection.
+func WithBodyCapture(maxBytes int, r *packtrack.Redactor) TransportOption {
+	return func(c *transportConfig) {
+		c.captureMax = maxBytes
+		c.redactor = r
+	}
+}
+
+// WithTransportSkip passes requests for which fn returns true through
+// uninstrumented.
+func WithTransportSkip(fn func(*http.Request) bool) TransportOption {
+	return func(c *transportConfig) { c.skip = fn }
+}
+
+// WithoutPropagation stops the transport from injecting workflow headers,
+// e.g. for third-party APIs that reject unknown headers.
+func WithoutPropagation() TransportOption {
+	return func(c *transportConfig) { c.noPropagate = true }
+}
+
+type transport struct {
+	client packtrack.AsyncClient
+	base   http.RoundTripper
+	cfg    transportConfig
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil) so that every
+// outbound request enqueues an event on client with host, path, status,
+// latency, retries, and error class. The workflow carried by the request
+// context is injected as headers (see packtrack.Inject).
+//
+// Do not use it for the HTTP client of the PackTrack Client itself: each
+// ingest request would emit another event.
+func NewTransport(client packtrack.AsyncClient, base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &transport{client: client, base: base}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&t.cfg)
+		}
+	}
+	if t.cfg.captureMax > 0 && t.cfg.redactor == nil {
+		t.cfg.redactor = packtrack.NewRedactor(packtrack.DefaultRedactionConfig())
+	}
+	return t
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cfg.skip != nil && t.cfg.skip(req) {
+		return t.base.RoundTrip(req)
+	}
+	out := req.Clone(req.Context())
+	if !t.cfg.noPropagate {
+		packtrack.Inject(req.Context(), packtrack.HeaderCarrier(out.Header))
+	}
+
+	start := time.Now()
+	var (
+		resp    *http.Response
+		err     error
+		reqBody *capture
+		retries int
+	)
+	for attempt := 0; ; attempt++ {
+		reqBody = t.wrapRequestBody(out)
+		resp, err = t.base.RoundTrip(out)
+		if attempt >= t.cfg.retries || !retryable(resp, err) {
+			break
+		}
+		body, ok := rewind(req)
+		if !ok {
+			break
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if !sleepCtx(req.Context(), t.cfg.backoff.Next(attempt)) {
+			if body != nil {
+				body.Close()
+			}
+			resp, err = nil, req.Context().Err()
+			break
+		}
+		out.Body = body
+		retries++
+	}
+	latency := time.Since(start)
+
+	if resp == nil || t.cfg.captureMax <= 0 || resp.Body == http.NoBody ||
+		resp.StatusCode == http.StatusSwitchingProtocols {
+		t.emit(req, resp, err, latency, retries, reqBody, nil)
+		return resp, err
+	}
+	respBody := &capture{max: t.cfg.captureMax}
+	resp.Body = &emitOnClose{
+		ReadCloser: resp.Body,
+		capture:    respBody,
+		emit: func() {
+			t.emit(req, resp, nil, latency, retries, reqBody, respBody)
+		},
+	}
+	return resp, nil
+}
+
+func (t *transport) wrapRequestBody(out *http.Request) *capture {
+	if t.cfg.captureMax <= 0 || out.Body == nil || out.Body == http.NoBody {
+		return nil
+	}
+	c := &capture{max: t.cfg.captureMax}
+	out.Body = &captureReader{ReadCloser: out.Body, capture: c}
+	return c
+}
+
+func (t *transport) emit(req *http.Request, resp *http.Response, err error, latency time.Duration, retries int, reqBody, respBody *capture) {
+	wf, _ := packtrack.WorkflowFromContext(req.Context())
+	e := packtrack.Event{
+		Timestamp: time.Now().UTC(),
+		Workflow:  wf,
+		Severity:  packtrack.SeverityInfo,
+		Status:    packtrack.StatusSuccess,
+		Metadata: map[string]any{
+			MetaMethod:     req.Method,
+			MetaHost:       req.URL.Host,
+			MetaPath:       req.URL.Path,
+			MetaDurationMS: latency.Milliseconds(),
+			MetaRetries:    retries,
+		},
+	}
+	target := req.Method + " " + req.URL.Host + req.URL.Path
+	var class string
+	if err != nil {
+		class = classifyError(err)
+		e.Severity, e.Status = packtrack.SeverityError, packtrack.StatusError
+		e.Message = target + " failed: " + class
+		e.Metadata[packtrack.MetaError] = err.Error()
+	} else {
+		class = classifyStatus(resp.StatusCode)
+		e.Metadata[MetaStatusCode] = resp.StatusCode
+		e.Message = target + " " + strconv.Itoa(resp.StatusCode)
+		switch {
+		case resp.StatusCode >= 500:
+			e.Severity, e.Status = packtrack.SeverityError, packtrack.StatusError
+		case resp.StatusCode >= 400:
+			e.Severity = packtrack.SeverityWarn
+		}
+	}
+	if class != "" {
+		e.Metadata[MetaErrorClass] = class
+	}
+	if s, ok := reqBody.text(t.cfg.redactor); ok {
+		e.Metadata[MetaRequestBody] = s
+	}
+	if s, ok := respBody.text(t.cfg.redactor); ok {
+		e.Metadata[MetaResponseBody] = s
+	}
+	_ = t.client.Enqueue(e)
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewind returns a fresh copy of the request body for another attempt.
+func rewind(req *http.Request) (io.ReadCloser, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Body, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	return body, err == nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func classifyStatus(code int) string {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case code >= 500:
+		return ErrorClassServer
+	case code >= 400:
+		return ErrorClassClient
+	}
+	return ""
+}
+
+func classifyError(err error) string {
+	var (
+		dnsErr  *net.DNSError
+		netErr  net.Error
+		certErr *tls.CertificateVerificationError
+		recErr  tls.RecordHeaderError
+		authErr x509.UnknownAuthorityError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.As(err, &dnsErr):
+		return ErrorClassDNS
+	case errors.As(err, &certErr), errors.As(err, &recErr), errors.As(err, &authErr):
+		return ErrorClassTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorClassRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorClassReset
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	}
+	return ErrorClassNetwork
+}
+
+// capture records the first max bytes read through it. The transport may
+// read request bodies from another goroutine, hence the mutex.
+type capture struct {
+	mu        sync.Mutex
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func (c *capture) record(p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if room := c.max - len(c.buf); room < len(p) {
+		c.truncated = true
+		p = p[:max(room, 0)]
+	}
+	c.buf = append(c.buf, p...)
+}
+
+// text returns the redacted capture. ok is false for a nil capture or an
+// empty body.
+func (c *capture) text(r *packtrack.Redactor) (s string, ok bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.buf) == 0 {
+		return "", false
+	}
+	b := c.buf
+	if c.truncated {
+		// Do not split a multi-byte rune at the cut.
+		for i := 0; i < utf8.UTFMax && len(b) > 0 && !utf8.Valid(b); i++ {
+			b = b[:len(b)-1]
+		}
+	}
+	if !utf8.Valid(b) {
+		return "[binary, " + strconv.Itoa(len(c.buf)) + " bytes captured]", true
+	}
+	s = r.RedactString(string(b))
+	if c.truncated {
+		s += truncatedBodyMarker
+	}
+	return s, true
+}
+
+type captureReader struct {
+	io.ReadCloser
+	capture *capture
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.record(p[:n])
+	return n, err
+}
+
+// emitOnClose captures a response body and emits the event once, at EOF,
+// on a read error, or on Close, whichever comes first.
+type emitOnClose struct {
+	io.ReadCloser
+	capture *capture
+	emit    func()
+	once    sync.Once
+}
+
+func (r *emitOnClose) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.record(p[:n])
+	if err != nil {
+		r.once.Do(r.emit)
+	}
+	return n, err
+}
+
+func (r *emitOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.emit)
+	return err
+}
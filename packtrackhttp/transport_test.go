@@ -0,0 +1,182 @@
+package packtrackhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func TestTransport(t *testing.T) {
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	fake := &packtracktest.AsyncClient{}
+	hc := &http.Client{Transport: NewTransport(fake, nil)}
+	ctx := packtrack.ContextWithWorkflow(context.Background(), packtrack.Workflow{ID: "wf-1", RunID: "run-1"})
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/v1/tools", nil)
+	resp, err := hc.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if gotHeader.Get(packtrack.HeaderWorkflowID) != "wf-1" || gotHeader.Get(packtrack.HeaderRunID) != "run-1" {
+		t.Errorf("propagated headers = %v", gotHeader)
+	}
+	if req.Header.Get(packtrack.HeaderWorkflowID) != "" {
+		t.Error("caller's request was mutated")
+	}
+	events := fake.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events", len(events))
+	}
+	e := events[0]
+	md := e.Metadata
+	if md[MetaHost] != req.URL.Host || md[MetaPath] != "/v1/tools" || md[MetaStatusCode] != 404 ||
+		md[MetaRetries] != 0 || md[MetaErrorClass] != ErrorClassClient {
+		t.Errorf("metadata = %v", md)
+	}
+	if e.Severity != packtrack.SeverityWarn || e.Workflow.ID != "wf-1" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	var calls atomic.Int32
+	var lastBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastBody = string(b)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	fake := &packtracktest.AsyncClient{}
+	hc := &http.Client{Transport: NewTransport(fake, nil, WithRetries(3, time.Millisecond, 5*time.Millisecond))}
+	resp, err := hc.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if calls.Load() != 3 || lastBody != "payload" {
+		t.Fatalf("calls = %d, last body = %q", calls.Load(), lastBody)
+	}
+	events := fake.Events()
+	if len(events) != 1 || events[0].Metadata[MetaRetries] != 2 || events[0].Metadata[MetaStatusCode] != 200 {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestTransportBodyCapture(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		io.WriteString(w, `{"answer":"mail ops@example.com for access","padding":"xxxxxxxxxxxxxxxxxxxxxxxxxxxx"}`)
+	}))
+	defer srv.Close()
+
+	fake := &packtracktest.AsyncClient{}
+	hc := &http.Client{Transport: NewTransport(fake, nil, WithBodyCapture(48, nil))}
+	resp, err := hc.Post(srv.URL, "application/json", strings.NewReader(`{"q":"hi","auth":"Bearer abc.def"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Events()) != 0 {
+		t.Fatal("event emitted before the response body was consumed")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "ops@example.com") {
+		t.Fatalf("caller saw a modified body: %s", body)
+	}
+
+	events := fake.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events", len(events))
+	}
+	md := events[0].Metadata
+	if got := md[MetaRequestBody]; got != `{"q":"hi","auth":"[REDACTED]"}` {
+		t.Errorf("request body = %v", got)
+	}
+	got, _ := md[MetaResponseBody].(string)
+	if !strings.HasSuffix(got, truncatedBodyMarker) || strings.Contains(got, "ops@example.com") ||
+		!strings.Contains(got, packtrack.DefaultRedactionMask) {
+		t.Errorf("response body = %q", got)
+	}
+}
+
+func TestTransportBodyCaptureKeepsUpgrade(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString(line)
+		rw.Flush()
+	}))
+	defer srv.Close()
+
+	fake := &packtracktest.AsyncClient{}
+	hc := &http.Client{Transport: NewTransport(fake, nil, WithBodyCapture(64, nil))}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "echo")
+	resp, err := hc.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	rw, ok := resp.Body.(io.ReadWriter)
+	if !ok {
+		t.Fatalf("101 body lost io.Writer: %T", resp.Body)
+	}
+	if events := fake.Events(); len(events) != 1 || events[0].Metadata[MetaStatusCode] != http.StatusSwitchingProtocols {
+		t.Fatalf("expected an immediate 101 event, got %+v", events)
+	}
+	io.WriteString(rw, "ping\n")
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(rw, buf); err != nil || string(buf) != "ping\n" {
+		t.Fatalf("upgraded echo = %q, %v", buf, err)
+	}
+}
+
+func TestTransportErrorClass(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	fake := &packtracktest.AsyncClient{}
+	hc := &http.Client{Transport: NewTransport(fake, nil)}
+	if _, err := hc.Get(url); err == nil {
+		t.Fatal("expected an error")
+	}
+	events := fake.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events", len(events))
+	}
+	e := events[0]
+	if e.Severity != packtrack.SeverityError || e.Metadata[MetaErrorClass] != ErrorClassRefused {
+		t.Errorf("event = %+v", e)
+	}
+}
@@ -0,0 +1,370 @@
+// Package packtrackotlp converts OpenTelemetry logs, in the OTLP/HTTP JSON
+// encoding of ExportLogsServiceRequest, into PackTrack events, and provides
+// an http.Handler that receives them and forwards them to an AsyncClient.
+package packtrackotlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// Metadata keys set on converted events. Log record attributes are copied
+// into Metadata under their own names.
+const (
+	MetaTraceID      = "trace_id"
+	MetaSpanID       = "span_id"
+	MetaSeverityText = "otel.severity_text"
+	MetaEventName    = "otel.event_name"
+	MetaScopeName    = "otel.scope.name"
+	MetaScopeVersion = "otel.scope.version"
+	MetaResource     = "otel.resource" // resource attributes not mapped to Source
+)
+
+// Resource attribute keys mapped to Source.
+const (
+	AttrServiceName           = "service.name"
+	AttrDeploymentEnvironment = "deployment.environment.name"
+	attrDeploymentEnvLegacy   = "deployment.environment"
+)
+
+// Log record attribute keys mapped to Workflow fields instead of Metadata,
+// matching the packtrackslog handler.
+const (
+	AttrWorkflowID   = "workflow_id"
+	AttrWorkflowName = "workflow_name"
+	AttrRunID        = "run_id"
+	AttrStepID       = "step_id"
+)
+
+// ConvertLogs decodes an OTLP/HTTP JSON ExportLogsServiceRequest and returns
+// one event per log record:
+//
+//   - service.name and deployment.environment.name (or the older
+//     deployment.environment) resource attributes set Source; other resource
+//     attributes go under Metadata[MetaResource].
+//   - Severity numbers map to Severity by range (TRACE and DEBUG are debug,
+//     INFO is info, WARN is warn, ERROR and FATAL are error). Records with an
+//     unspecified number fall back to the severity text, then to info.
+//   - Error records get StatusError; all others StatusSuccess.
+//   - Trace and span IDs, severity text, event name, and instrumentation
+//     scope are set in Metadata, as are the record's attributes, except
+//     workflow_id, workflow_name, run_id, and step_id, which set Workflow.
+//   - A string body is the message; other bodies are JSON-encoded.
+//
+// Actor is left empty for the client's WithDefaultActor to fill, and
+// Timestamp falls back to the observed time, then to empty.
+func ConvertLogs(data []byte) ([]packtrack.Event, error) {
+	var req exportLogsRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("packtrackotlp: decode logs: %w", err)
+	}
+	var events []packtrack.Event
+	for _, rl := range req.ResourceLogs {
+		src, resource := resourceSource(rl.Resource.Attributes)
+		for _, sl := range rl.ScopeLogs {
+			for _, lr := range sl.LogRecords {
+				e := convertRecord(lr)
+				e.Source = src
+				if len(resource) > 0 {
+					e.Metadata[MetaResource] = copyMap(resource)
+				}
+				if sl.Scope.Name != "" {
+					e.Metadata[MetaScopeName] = sl.Scope.Name
+				}
+				if sl.Scope.Version != "" {
+					e.Metadata[MetaScopeVersion] = sl.Scope.Version
+				}
+				events = append(events, e)
+			}
+		}
+	}
+	return events, nil
+}
+
+func resourceSource(attrs []keyValue) (packtrack.Source, map[string]any) {
+	var src packtrack.Source
+	rest := make(map[string]any)
+	for _, kv := range attrs {
+		switch kv.Key {
+		case AttrServiceName:
+			src.System = kv.Value.string()
+		case AttrDeploymentEnvironment:
+			src.Env = kv.Value.string()
+		case attrDeploymentEnvLegacy:
+			if src.Env == "" {
+				src.Env = kv.Value.string()
+			}
+		default:
+			rest[kv.Key] = kv.Value.value()
+		}
+	}
+	return src, rest
+}
+
+func convertRecord(lr logRecord) packtrack.Event {
+	e := packtrack.Event{
+		Severity: severity(int(lr.SeverityNumber), lr.SeverityText),
+		Status:   packtrack.StatusSuccess,
+		Message:  lr.Body.string(),
+		Metadata: make(map[string]any),
+	}
+	if e.Severity == packtrack.SeverityError {
+		e.Status = packtrack.StatusError
+	}
+	switch {
+	case lr.TimeUnixNano > 0:
+		e.Timestamp = time.Unix(0, int64(lr.TimeUnixNano)).UTC()
+	case lr.ObservedTimeUnixNano > 0:
+		e.Timestamp = time.Unix(0, int64(lr.ObservedTimeUnixNano)).UTC()
+	}
+	if lr.TraceID != "" {
+		e.Metadata[MetaTraceID] = lr.TraceID
+	}
+	if lr.SpanID != "" {
+		e.Metadata[MetaSpanID] = lr.SpanID
+	}
+	if lr.SeverityText != "" {
+		e.Metadata[MetaSeverityText] = lr.SeverityText
+	}
+	if lr.EventName != "" {
+		e.Metadata[MetaEventName] = lr.EventName
+	}
+	for _, kv := range lr.Attributes {
+		switch kv.Key {
+		case AttrWorkflowID:
+			e.Workflow.ID = kv.Value.string()
+		case AttrWorkflowName:
+			e.Workflow.Name = kv.Value.string()
+		case AttrRunID:
+			e.Workflow.RunID = kv.Value.string()
+		case AttrStepID:
+			e.Workflow.StepID = kv.Value.string()
+		default:
+			e.Metadata[kv.Key] = kv.Value.value()
+		}
+	}
+	return e
+}
+
+// Severity maps an OTLP severity number (1-24) to a PackTrack severity. It
+// returns 0 for SEVERITY_NUMBER_UNSPECIFIED and out-of-range numbers.
+func Severity(number int) packtrack.Severity {
+	switch {
+	case number >= 1 && number <= 8:
+		return packtrack.SeverityDebug
+	case number >= 9 && number <= 12:
+		return packtrack.SeverityInfo
+	case number >= 13 && number <= 16:
+		return packtrack.SeverityWarn
+	case number >= 17 && number <= 24:
+		return packtrack.SeverityError
+	}
+	return 0
+}
+
+func severity(number int, text string) packtrack.Severity {
+	if s := Severity(number); s != 0 {
+		return s
+	}
+	switch strings.ToLower(text) {
+	case "trace", "debug":
+		return packtrack.SeverityDebug
+	case "warn", "warning":
+		return packtrack.SeverityWarn
+	case "error", "fatal", "critical":
+		return packtrack.SeverityError
+	}
+	return packtrack.SeverityInfo
+}
+
+func copyMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// The types below mirror the OTLP protobuf messages in their JSON encoding:
+// lowerCamelCase field names, 64-bit integers as decimal strings, and trace
+// and span IDs as hex strings.
+
+type exportLogsRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type scope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type logRecord struct {
+	TimeUnixNano         uint64s        `json:"timeUnixNano"`
+	ObservedTimeUnixNano uint64s        `json:"observedTimeUnixNano"`
+	SeverityNumber       severityNumber `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 anyValue       `json:"body"`
+	Attributes           []keyValue     `json:"attributes"`
+	TraceID              string         `json:"traceId"`
+	SpanID               string         `json:"spanId"`
+	EventName            string         `json:"eventName"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string      `json:"stringValue"`
+	BoolValue   *bool        `json:"boolValue"`
+	IntValue    *int64s      `json:"intValue"`
+	DoubleValue *float64     `json:"doubleValue"`
+	BytesValue  *string      `json:"bytesValue"` // base64
+	ArrayValue  *arrayValue  `json:"arrayValue"`
+	KvlistValue *kvlistValue `json:"kvlistValue"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+type kvlistValue struct {
+	Values []keyValue `json:"values"`
+}
+
+// value returns v as a Go value suitable for Metadata. Bytes are kept as
+// their base64 text.
+func (v anyValue) value() any {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		return int64(*v.IntValue)
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		out := make([]any, len(v.ArrayValue.Values))
+		for i, e := range v.ArrayValue.Values {
+			out[i] = e.value()
+		}
+		return out
+	case v.KvlistValue != nil:
+		out := make(map[string]any, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			out[kv.Key] = kv.Value.value()
+		}
+		return out
+	}
+	return nil
+}
+
+// string returns v as text: strings as is, scalars formatted, and arrays and
+// maps JSON-encoded. An empty value is "".
+func (v anyValue) string() string {
+	switch x := v.value().(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// uint64s and int64s accept the string encoding OTLP JSON uses for 64-bit
+// integers as well as plain numbers.
+type uint64s uint64
+
+func (n *uint64s) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	v, err := strconv.ParseUint(strings.Trim(string(b), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64 %s", b)
+	}
+	*n = uint64s(v)
+	return nil
+}
+
+type int64s int64
+
+func (n *int64s) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	v, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %s", b)
+	}
+	*n = int64s(v)
+	return nil
+}
+
+// severityNumber accepts the integer encoding of the SeverityNumber enum and,
+// as protobuf JSON parsers do, its name (e.g. "SEVERITY_NUMBER_WARN2").
+type severityNumber int
+
+var severityNames = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+func (n *severityNumber) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if v, err := strconv.Atoi(string(b)); err == nil {
+		*n = severityNumber(v)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("invalid severity number %s", b)
+	}
+	name = strings.TrimPrefix(name, "SEVERITY_NUMBER_")
+	for i, base := range severityNames {
+		rest, ok := strings.CutPrefix(name, base)
+		if !ok {
+			continue
+		}
+		step := 1
+		if rest != "" {
+			if step, _ = strconv.Atoi(rest); step < 2 || step > 4 {
+				break
+			}
+		}
+		*n = severityNumber(i*4 + step)
+		return nil
+	}
+	*n = 0 // UNSPECIFIED or unknown
+	return nil
+}
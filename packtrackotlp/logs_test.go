@@ -0,0 +1,178 @@
+package packtrackotlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestConvertLogs(t *testing.T) {
+	events, err := ConvertLogs(readFixture(t, "logs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d events", len(events))
+	}
+
+	plan := events[0]
+	if plan.Source != (packtrack.Source{System: "support-agent", Env: "prod"}) {
+		t.Errorf("source = %+v", plan.Source)
+	}
+	if plan.Workflow != (packtrack.Workflow{ID: "wf-support", RunID: "run-77"}) {
+		t.Errorf("workflow = %+v", plan.Workflow)
+	}
+	if !plan.Timestamp.Equal(time.Unix(0, 1760781600123456789)) || plan.Severity != packtrack.SeverityInfo ||
+		plan.Status != packtrack.StatusSuccess || plan.Message != "plan created" {
+		t.Errorf("event = %+v", plan)
+	}
+	want := map[string]any{
+		MetaTraceID:      "5b8efff798038103d269b633813fc60c",
+		MetaSpanID:       "eee19b7ec3c1b174",
+		MetaSeverityText: "INFO",
+		MetaScopeName:    "agent.planner",
+		MetaScopeVersion: "0.9.0",
+		MetaResource:     map[string]any{"service.version": "1.4.2", "telemetry.sdk.language": "python"},
+		"plan.steps":     int64(4),
+		"plan.cached":    false,
+		"plan.score":     0.82,
+		"plan.tools":     []any{"search", "email"},
+	}
+	if !reflect.DeepEqual(plan.Metadata, want) {
+		t.Errorf("metadata =\n%#v\nwant\n%#v", plan.Metadata, want)
+	}
+
+	failed := events[1]
+	if failed.Severity != packtrack.SeverityError || failed.Status != packtrack.StatusError {
+		t.Errorf("severity/status = %v/%v", failed.Severity, failed.Status)
+	}
+	if !failed.Timestamp.Equal(time.Unix(0, 1760781601000000000)) {
+		t.Errorf("observed time fallback = %v", failed.Timestamp)
+	}
+	if failed.Message != `{"error":"tool timeout","tool":"email"}` || failed.Workflow.StepID != "send-email" {
+		t.Errorf("event = %+v", failed)
+	}
+
+	retry := events[2]
+	if retry.Source != (packtrack.Source{System: "batch-worker", Env: "staging"}) ||
+		retry.Severity != packtrack.SeverityWarn || retry.Metadata[MetaEventName] != "job.retry" {
+		t.Errorf("event = %+v", retry)
+	}
+	if _, ok := retry.Metadata[MetaResource]; ok {
+		t.Error("empty resource remainder was kept")
+	}
+	if events[3].Severity != packtrack.SeverityDebug {
+		t.Errorf("severity text fallback = %v", events[3].Severity)
+	}
+
+	if _, err := ConvertLogs(readFixture(t, "invalid.json")); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for n, want := range map[int]packtrack.Severity{
+		0: 0, 1: packtrack.SeverityDebug, 8: packtrack.SeverityDebug, 9: packtrack.SeverityInfo,
+		13: packtrack.SeverityWarn, 17: packtrack.SeverityError, 24: packtrack.SeverityError, 25: 0,
+	} {
+		if got := Severity(n); got != want {
+			t.Errorf("Severity(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	h := NewHandler(fake, WithDefaultWorkflow(packtrack.Workflow{ID: "otel", Name: "OTel logs"}))
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(readFixture(t, "logs.json"))
+	zw.Close()
+	req := httptest.NewRequest(http.MethodPost, LogsPath, &gz)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "{}" {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body)
+	}
+	events := fake.Events()
+	if len(events) != 4 {
+		t.Fatalf("enqueued %d events", len(events))
+	}
+	if wf := events[0].Workflow; wf != (packtrack.Workflow{ID: "wf-support", RunID: "run-77"}) {
+		t.Errorf("record workflow overridden: %+v", wf)
+	}
+	if wf := events[2].Workflow; wf != (packtrack.Workflow{ID: "otel", Name: "OTel logs"}) {
+		t.Errorf("default workflow not applied: %+v", wf)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		code int
+		body string
+	}{
+		{packtrack.ErrQueueFull, http.StatusServiceUnavailable, "queue full"},
+		{packtrack.ErrClosed, http.StatusServiceUnavailable, "closed"},
+		{errors.New("invalid event"), http.StatusOK, `"rejectedLogRecords":"4"`},
+	} {
+		fake.SetErr(tc.err)
+		req = httptest.NewRequest(http.MethodPost, LogsPath, bytes.NewReader(readFixture(t, "logs.json")))
+		req.Header.Set("Content-Type", "application/json")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.code || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Errorf("%v: status %d body %s", tc.err, rec.Code, rec.Body)
+		}
+		if retry := rec.Header().Get("Retry-After"); (tc.code == http.StatusServiceUnavailable) != (retry == "1") {
+			t.Errorf("%v: Retry-After = %q", tc.err, retry)
+		}
+	}
+	fake.SetErr(nil)
+
+	for _, tc := range []struct {
+		method, contentType, body string
+		want                      int
+	}{
+		{http.MethodGet, "application/json", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "application/x-protobuf", "", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "application/json", "{", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, LogsPath, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.contentType, rec.Code, tc.want)
+		}
+	}
+
+	small := NewHandler(fake, WithMaxBodyBytes(10))
+	req = httptest.NewRequest(http.MethodPost, LogsPath, bytes.NewReader(readFixture(t, "logs.json")))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	small.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: status %d", rec.Code)
+	}
+}
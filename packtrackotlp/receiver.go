@@ -0,0 +1,153 @@
+package packtrackotlp
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// LogsPath is the OTLP/HTTP path for logs.
+const LogsPath = "/v1/logs"
+
+// DefaultMaxBodyBytes bounds a request body, after decompression.
+const DefaultMaxBodyBytes = 4 << 20
+
+// RetryAfterSeconds is the Retry-After value sent with 503 responses.
+const RetryAfterSeconds = 1
+
+// Option configures a receiver Handler.
+type Option func(*config)
+
+type config struct {
+	workflow packtrack.Workflow
+	maxBody  int64
+}
+
+// WithDefaultWorkflow fills the empty Workflow fields of records that name no
+// workflow, or the same one, in place of any workflow in the request context.
+func WithDefaultWorkflow(wf packtrack.Workflow) Option {
+	return func(c *config) { c.workflow = wf }
+}
+
+// WithMaxBodyBytes overrides DefaultMaxBodyBytes.
+func WithMaxBodyBytes(n int64) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxBody = n
+		}
+	}
+}
+
+// NewHandler returns an http.Handler accepting OTLP/HTTP JSON log exports,
+// as sent by OpenTelemetry exporters to LogsPath. Each record is converted
+// with ConvertLogs and enqueued on client. When the client's queue is full or
+// it is closed, the request is answered with 503 Service Unavailable and a
+// Retry-After header, so exporters retry it; records enqueued before that
+// may then be received twice. Records rejected for other reasons, such as
+// failing validation, are reported back as a partial success. Protobuf
+// payloads are answered with 415 Unsupported Media Type; gzip request bodies
+// are accepted.
+func NewHandler(client packtrack.AsyncClient, opts ...Option) http.Handler {
+	cfg := config{maxBody: DefaultMaxBodyBytes}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeStatus(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+			writeStatus(w, http.StatusUnsupportedMediaType, "only application/json is supported")
+			return
+		}
+		body := io.Reader(r.Body)
+		switch r.Header.Get("Content-Encoding") {
+		case "", "identity":
+		case "gzip":
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				writeStatus(w, http.StatusBadRequest, "invalid gzip body")
+				return
+			}
+			defer zr.Close()
+			body = zr
+		default:
+			writeStatus(w, http.StatusUnsupportedMediaType, "unsupported content encoding")
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(body, cfg.maxBody+1))
+		switch {
+		case err != nil:
+			writeStatus(w, http.StatusBadRequest, "read body: "+err.Error())
+			return
+		case int64(len(data)) > cfg.maxBody:
+			writeStatus(w, http.StatusRequestEntityTooLarge, "body too large")
+			return
+		}
+		events, err := ConvertLogs(data)
+		if err != nil {
+			writeStatus(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var rejected int64
+		var first error
+		ctx := r.Context()
+		if cfg.workflow != (packtrack.Workflow{}) {
+			ctx = packtrack.ContextWithWorkflow(ctx, cfg.workflow)
+		}
+		for _, e := range events {
+			err := packtrack.EnqueueContext(ctx, client, e)
+			if errors.Is(err, packtrack.ErrQueueFull) || errors.Is(err, packtrack.ErrClosed) {
+				w.Header().Set("Retry-After", strconv.Itoa(RetryAfterSeconds))
+				writeStatus(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+			if err != nil {
+				rejected++
+				if first == nil {
+					first = err
+				}
+			}
+		}
+		resp := exportLogsResponse{}
+		if rejected > 0 {
+			resp.PartialSuccess = &partialSuccess{
+				RejectedLogRecords: rejected,
+				ErrorMessage:       fmt.Sprintf("%d of %d records rejected: %v", rejected, len(events), first),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+type exportLogsResponse struct {
+	PartialSuccess *partialSuccess `json:"partialSuccess,omitempty"`
+}
+
+type partialSuccess struct {
+	RejectedLogRecords int64  `json:"rejectedLogRecords,string"`
+	ErrorMessage       string `json:"errorMessage,omitempty"`
+}
+
+// writeStatus answers with a JSON google.rpc.Status, as OTLP/HTTP requires
+// for errors.
+func writeStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+	}{msg})
+}
@@ -0,0 +1,204 @@
+// Package packtrackslog provides a log/slog Handler that ships records to
+// PackTrack through an AsyncClient.
+package packtrackslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// Top-level attribute keys mapped to Workflow fields instead of Metadata.
+const (
+	AttrWorkflowID   = "workflow_id"
+	AttrWorkflowName = "workflow_name"
+	AttrRunID        = "run_id"
+	AttrStepID       = "step_id"
+)
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithLevel sets the minimum level handled. Defaults to slog.LevelInfo.
+func WithLevel(l slog.Leveler) Option { return func(h *Handler) { h.level = l } }
+
+// WithSource records the caller's function, file, and line under
+// Metadata[slog.SourceKey].
+func WithSource() Option { return func(h *Handler) { h.addSource = true } }
+
+// Handler is a slog.Handler that enqueues each record as a packtrack.Event.
+// Levels map to Severity (below Info is debug, Error and above is error),
+// attributes and groups become nested Metadata, and top-level workflow_id,
+// workflow_name, run_id, and step_id attributes set Workflow fields. The
+// workflow carried by the Handle context fills fields the attributes leave
+// empty.
+type Handler struct {
+	client    packtrack.AsyncClient
+	level     slog.Leveler
+	addSource bool
+	goas      []groupOrAttrs
+}
+
+// groupOrAttrs is one WithGroup or WithAttrs call.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler returns a Handler enqueuing on client.
+func NewHandler(client packtrack.AsyncClient, opts ...Option) *Handler {
+	h := &Handler{client: client, level: slog.LevelInfo}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *Handler) with(g groupOrAttrs) *Handler {
+	h2 := *h
+	h2.goas = append(slices.Clip(h.goas), g)
+	return &h2
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	e := packtrack.Event{
+		Severity: Severity(r.Level),
+		Status:   packtrack.StatusSuccess,
+		Message:  r.Message,
+		Metadata: make(map[string]any),
+	}
+	if !r.Time.IsZero() {
+		e.Timestamp = r.Time.UTC()
+	}
+	if e.Severity == packtrack.SeverityError {
+		e.Status = packtrack.StatusError
+	}
+	if h.addSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e.Metadata[slog.SourceKey] = map[string]any{"function": f.Function, "file": f.File, "line": f.Line}
+	}
+
+	goas := h.goas
+	if r.NumAttrs() == 0 {
+		// Groups without attributes are omitted.
+		for len(goas) > 0 && goas[len(goas)-1].group != "" {
+			goas = goas[:len(goas)-1]
+		}
+	}
+	cur, wf := e.Metadata, &e.Workflow
+	for _, g := range goas {
+		if g.group != "" {
+			m := make(map[string]any)
+			cur[g.group] = m
+			cur, wf = m, nil
+			continue
+		}
+		for _, a := range g.attrs {
+			addAttr(cur, a, wf)
+		}
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(cur, a, wf)
+		return true
+	})
+
+	return packtrack.EnqueueContext(ctx, h.client, e)
+}
+
+// Severity maps a slog level to a packtrack.Severity.
+func Severity(l slog.Level) packtrack.Severity {
+	switch {
+	case l < slog.LevelInfo:
+		return packtrack.SeverityDebug
+	case l < slog.LevelWarn:
+		return packtrack.SeverityInfo
+	case l < slog.LevelError:
+		return packtrack.SeverityWarn
+	default:
+		return packtrack.SeverityError
+	}
+}
+
+// addAttr adds a to m. wf is non-nil only at the top level, where workflow
+// attributes are diverted to it.
+func addAttr(m map[string]any, a slog.Attr, wf *packtrack.Workflow) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				addAttr(m, ga, wf)
+			}
+			return
+		}
+		sub := make(map[string]any, len(attrs))
+		for _, ga := range attrs {
+			addAttr(sub, ga, nil)
+		}
+		if len(sub) > 0 {
+			m[a.Key] = sub
+		}
+		return
+	}
+	if wf != nil && a.Value.Kind() == slog.KindString {
+		switch a.Key {
+		case AttrWorkflowID:
+			wf.ID = a.Value.String()
+			return
+		case AttrWorkflowName:
+			wf.Name = a.Value.String()
+			return
+		case AttrRunID:
+			wf.RunID = a.Value.String()
+			return
+		case AttrStepID:
+			wf.StepID = a.Value.String()
+			return
+		}
+	}
+	m[a.Key] = value(a.Value)
+}
+
+func value(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.Any()
+}
@@ -0,0 +1,82 @@
+package packtrackslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"testing"
+	"testing/slogtest"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func TestSlogtest(t *testing.T) {
+	var fake *packtracktest.AsyncClient
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		fake = &packtracktest.AsyncClient{}
+		return NewHandler(fake)
+	}, func(*testing.T) map[string]any {
+		e := fake.Last()
+		m := maps.Clone(e.Metadata)
+		if !e.Timestamp.IsZero() {
+			m[slog.TimeKey] = e.Timestamp
+		}
+		m[slog.LevelKey] = e.Severity
+		m[slog.MessageKey] = e.Message
+		return m
+	})
+}
+
+func TestHandlerMapping(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	logger := slog.New(NewHandler(fake, WithLevel(slog.LevelDebug))).
+		With(AttrWorkflowID, "wf-1", "service", "billing")
+
+	ctx := packtrack.ContextWithWorkflow(context.Background(), packtrack.Workflow{ID: "wf-1", RunID: "run-ctx", StepID: "s-ctx"})
+	logger.WithGroup("req").ErrorContext(ctx, "charge failed",
+		AttrRunID, "run-1", // inside a group: plain metadata
+		"err", errors.New("card declined"),
+		slog.Group("card", "brand", "visa"),
+	)
+
+	e := fake.Last()
+	if e.Severity != packtrack.SeverityError || e.Status != packtrack.StatusError {
+		t.Errorf("severity/status = %v/%v", e.Severity, e.Status)
+	}
+	want := packtrack.Workflow{ID: "wf-1", RunID: "run-ctx", StepID: "s-ctx"}
+	if e.Workflow != want {
+		t.Errorf("workflow = %+v, want %+v", e.Workflow, want)
+	}
+	req, _ := e.Metadata["req"].(map[string]any)
+	card, _ := req["card"].(map[string]any)
+	if e.Metadata["service"] != "billing" || req[AttrRunID] != "run-1" || req["err"] != "card declined" || card["brand"] != "visa" {
+		t.Errorf("metadata = %v", e.Metadata)
+	}
+
+	logger.Debug("step", AttrRunID, "run-2", AttrStepID, "s-2")
+	e = fake.Last()
+	if e.Severity != packtrack.SeverityDebug || e.Workflow.RunID != "run-2" || e.Workflow.StepID != "s-2" {
+		t.Errorf("event = %+v", e)
+	}
+	if _, ok := e.Metadata[AttrRunID]; ok {
+		t.Error("workflow attribute kept in metadata")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for l, want := range map[slog.Level]packtrack.Severity{
+		slog.LevelDebug - 4: packtrack.SeverityDebug,
+		slog.LevelDebug:     packtrack.SeverityDebug,
+		slog.LevelInfo:      packtrack.SeverityInfo,
+		slog.LevelInfo + 2:  packtrack.SeverityInfo,
+		slog.LevelWarn:      packtrack.SeverityWarn,
+		slog.LevelError:     packtrack.SeverityError,
+		slog.LevelError + 4: packtrack.SeverityError,
+	} {
+		if got := Severity(l); got != want {
+			t.Errorf("Severity(%v) = %v, want %v", l, got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package packtrack
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// EventProcessor inspects or modifies an event before it is queued or sent.
+// Returning keep=false drops the event. Processors run in registration order
+// after the severity threshold and sampler, and before redaction, size limits,
+// and strict validation.
+//
+// The event's top-level Metadata and Extra maps are non-nil private copies
+// that processors may modify in place; nested values are shared with the caller and
+// must be copied before modification.
+//
+// A processor that returns an error or panics is isolated: the failure is
+// counted via MetricsHooks.OnProcessorError, the event is kept, and the
+// remaining processors still run.
+type EventProcessor interface {
+	Process(ctx context.Context, e *Event) (keep bool, err error)
+}
+
+// EventProcessorFunc adapts an ordinary function to EventProcessor.
+type EventProcessorFunc func(ctx context.Context, e *Event) (bool, error)
+
+func (f EventProcessorFunc) Process(ctx context.Context, e *Event) (bool, error) { return f(ctx, e) }
+
+// Process implements EventProcessor.
+func (s *Sampler) Process(_ context.Context, e *Event) (bool, error) { return s.Sample(e), nil }
+
+// Process implements EventProcessor.
+func (r *Redactor) Process(_ context.Context, e *Event) (bool, error) {
+	r.Redact(e)
+	return true, nil
+}
+
+// runProcessors applies c.cfg.Processors in order, isolating failures.
+func (c *client) runProcessors(ctx context.Context, e *Event) bool {
+	if len(c.cfg.Processors) == 0 {
+		return true
+	}
+	e.Metadata = cloneOrMake(e.Metadata)
+	e.Extra = cloneOrMake(e.Extra)
+	for i, p := range c.cfg.Processors {
+		keep, err := safeProcess(ctx, p, e)
+		if err != nil {
+			err = fmt.Errorf("processor %d (%T): %w", i, p, err)
+			if c.cfg.Logger != nil {
+				c.cfg.Logger.Warnf("packtrack: %v", err)
+			}
+			c.cfg.MetricsHooks.processorError(err)
+			continue
+		}
+		if !keep {
+			c.cfg.MetricsHooks.dropped(DropReasonProcessor, 1)
+			return false
+		}
+	}
+	return true
+}
+
+func safeProcess(ctx context.Context, p EventProcessor, e *Event) (keep bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			keep, err = true, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.Process(ctx, e)
+}
+
+func cloneOrMake(m map[string]any) map[string]any {
+	if m == nil {
+		return make(map[string]any)
+	}
+	return maps.Clone(m)
+}
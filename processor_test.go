@@ -0,0 +1,87 @@
+package packtrack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProcessors_OrderDropAndIsolation(t *testing.T) {
+	var got []Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	var procErrs, dropped int32
+	hooks := &MetricsHooks{
+		OnProcessorError: func(error) { atomic.AddInt32(&procErrs, 1) },
+		OnDropped: func(reason string, n int) {
+			if reason == DropReasonProcessor {
+				atomic.AddInt32(&dropped, int32(n))
+			}
+		},
+	}
+	callerMeta := map[string]any{"a": 1}
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithMetricsHooks(hooks),
+		WithProcessors(
+			EventProcessorFunc(func(_ context.Context, e *Event) (bool, error) {
+				e.Metadata["step"] = "first"
+				return true, nil
+			}),
+			EventProcessorFunc(func(context.Context, *Event) (bool, error) { return true, errors.New("boom") }),
+			EventProcessorFunc(func(context.Context, *Event) (bool, error) { panic("kaboom") }),
+			EventProcessorFunc(func(_ context.Context, e *Event) (bool, error) {
+				e.Metadata["step"] = e.Metadata["step"].(string) + ",last"
+				return e.Message != "drop me", nil
+			}),
+		),
+	)
+	keep := newTestEvent()
+	keep.Metadata = callerMeta
+	drop := newTestEvent()
+	drop.Message = "drop me"
+	if _, err := c.IngestBatch(context.Background(), []Event{keep, drop}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Metadata["step"] != "first,last" {
+		t.Fatalf("unexpected events %+v", got)
+	}
+	if _, ok := callerMeta["step"]; ok {
+		t.Fatalf("caller metadata mutated")
+	}
+	if procErrs != 4 || dropped != 1 {
+		t.Fatalf("procErrs=%d dropped=%d", procErrs, dropped)
+	}
+}
+
+func TestProcessors_AsyncRunsOnceAtEnqueue(t *testing.T) {
+	var runs, sent int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithProcessors(
+		EventProcessorFunc(func(context.Context, *Event) (bool, error) {
+			atomic.AddInt32(&runs, 1)
+			return true, nil
+		}),
+	))
+	ac, _ := NewAsyncClient(c, WithFlushInterval(time.Hour))
+	_ = ac.Enqueue(newTestEvent())
+	_ = ac.Enqueue(newTestEvent())
+	if err := ac.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 || sent != 1 {
+		t.Fatalf("runs=%d sent=%d", runs, sent)
+	}
+	_ = ac.Close(context.Background())
+}
@@ -0,0 +1,197 @@
+package packtrack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request latency
+// histogram exposed by PrometheusMetrics.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// PrometheusMetrics collects the SDK's own metrics through MetricsHooks and
+// serves them in the Prometheus text exposition format. Pass Hooks to
+// WithMetricsHooks and mount the value as an http.Handler:
+//
+//	pm := packtrack.NewPrometheusMetrics()
+//	c, _ := packtrack.NewClient(packtrack.WithMetricsHooks(pm.Hooks()))
+//	http.Handle("/metrics", pm)
+type PrometheusMetrics struct {
+	mu              sync.Mutex
+	enqueued        uint64
+	sent            uint64
+	failed          uint64
+	batchesSent     uint64
+	batchesFailed   uint64
+	truncated       uint64
+	processorErrors uint64
+	queueDepth      int
+	backoff         bool // last ingest attempt failed; retrying or gave up
+	bytesRaw        uint64
+	bytesWire       uint64
+	dropped         map[string]uint64
+	retries         map[string]uint64
+	rejected        map[string]uint64
+	requests        map[string]uint64 // by status class, e.g. "2xx" or "error"
+	latencyCounts   []uint64          // len(latencyBuckets)+1
+	latencySum      float64
+	latencyCount    uint64
+	flushCounts     []uint64 // len(latencyBuckets)+1
+	flushSum        float64
+	flushCount      uint64
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		dropped:       make(map[string]uint64),
+		retries:       make(map[string]uint64),
+		rejected:      make(map[string]uint64),
+		requests:      make(map[string]uint64),
+		latencyCounts: make([]uint64, len(latencyBuckets)+1),
+		flushCounts:   make([]uint64, len(latencyBuckets)+1),
+	}
+}
+
+// Hooks returns MetricsHooks feeding p.
+func (p *PrometheusMetrics) Hooks() *MetricsHooks {
+	add := func(f func(n int)) func(int) {
+		return func(n int) {
+			p.mu.Lock()
+			f(n)
+			p.mu.Unlock()
+		}
+	}
+	return &MetricsHooks{
+		OnIngestSuccess: add(func(n int) { p.sent += uint64(n); p.batchesSent++; p.backoff = false }),
+		OnIngestFailure: add(func(n int) { p.failed += uint64(n); p.batchesFailed++; p.backoff = true }),
+		OnQueueDepth:    add(func(n int) { p.queueDepth = n }),
+		OnEnqueued:      add(func(n int) { p.enqueued += uint64(n) }),
+		OnTruncated:     add(func(n int) { p.truncated += uint64(n) }),
+		OnDropped: func(reason string, n int) {
+			p.mu.Lock()
+			p.dropped[reason] += uint64(n)
+			p.mu.Unlock()
+		},
+		OnProcessorError: func(error) {
+			p.mu.Lock()
+			p.processorErrors++
+			p.mu.Unlock()
+		},
+		OnRetry: func(_ int, reason string) {
+			p.mu.Lock()
+			p.retries[reason]++
+			p.backoff = true
+			p.mu.Unlock()
+		},
+		OnRequest: func(latency time.Duration, status int) {
+			class := "error"
+			if status > 0 {
+				class = strconv.Itoa(status/100) + "xx"
+			}
+			sec := latency.Seconds()
+			p.mu.Lock()
+			p.requests[class]++
+			p.latencyCounts[sort.SearchFloat64s(latencyBuckets, sec)]++
+			p.latencySum += sec
+			p.latencyCount++
+			p.mu.Unlock()
+		},
+		OnEnqueueRejected: func(reason string, n int) {
+			p.mu.Lock()
+			p.rejected[reason] += uint64(n)
+			p.mu.Unlock()
+		},
+		OnFlush: func(d time.Duration, _ int) {
+			sec := d.Seconds()
+			p.mu.Lock()
+			p.flushCounts[sort.SearchFloat64s(latencyBuckets, sec)]++
+			p.flushSum += sec
+			p.flushCount++
+			p.mu.Unlock()
+		},
+		OnPayload: func(raw, wire int) {
+			p.mu.Lock()
+			p.bytesRaw += uint64(raw)
+			p.bytesWire += uint64(wire)
+			p.mu.Unlock()
+		},
+	}
+}
+
+// ServeHTTP writes the current metrics in the Prometheus text format.
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	p.write(bw)
+	bw.Flush()
+}
+
+func (p *PrometheusMetrics) write(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	scalar := func(name, typ, help string, v float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, typ, name, formatFloat(v))
+	}
+	labeled := func(name, help, label string, m map[string]uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", name, label, escapeLabel(k), m[k])
+		}
+	}
+
+	scalar("packtrack_events_enqueued_total", "counter", "Events accepted by AsyncClient.Enqueue.", float64(p.enqueued))
+	labeled("packtrack_enqueue_rejected_total", "Events AsyncClient.Enqueue returned an error for, by reason.", "reason", p.rejected)
+	labeled("packtrack_events_dropped_total", "Events discarded client-side, by reason.", "reason", p.dropped)
+	scalar("packtrack_events_sent_total", "counter", "Events in ingest requests that succeeded.", float64(p.sent))
+	scalar("packtrack_events_failed_total", "counter", "Events in ingest requests that failed after retries.", float64(p.failed))
+	scalar("packtrack_batches_sent_total", "counter", "Ingest requests that succeeded.", float64(p.batchesSent))
+	scalar("packtrack_batches_failed_total", "counter", "Ingest requests that failed after retries.", float64(p.batchesFailed))
+	labeled("packtrack_retries_total", "Ingest request retries, by reason.", "reason", p.retries)
+	labeled("packtrack_requests_total", "Ingest HTTP attempts, by status class.", "code", p.requests)
+	scalar("packtrack_events_truncated_total", "counter", "Fields cut from events by size limits.", float64(p.truncated))
+	scalar("packtrack_processor_errors_total", "counter", "Event processors that failed or panicked.", float64(p.processorErrors))
+	scalar("packtrack_queue_depth", "gauge", "Events waiting in the AsyncClient queue.", float64(p.queueDepth))
+	backoff := 0.0
+	if p.backoff {
+		backoff = 1
+	}
+	scalar("packtrack_backoff", "gauge", "1 while ingest is retrying or its last request failed, 0 after a success.", backoff)
+	scalar("packtrack_payload_uncompressed_bytes_total", "counter", "Ingest payload bytes before compression.", float64(p.bytesRaw))
+	scalar("packtrack_payload_compressed_bytes_total", "counter", "Ingest payload bytes sent, after compression.", float64(p.bytesWire))
+
+	histogram := func(name, help string, counts []uint64, sum float64, count uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+		var cum uint64
+		for i, c := range counts {
+			cum += c
+			le := "+Inf"
+			if i < len(latencyBuckets) {
+				le = formatFloat(latencyBuckets[i])
+			}
+			fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, le, cum)
+		}
+		fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, formatFloat(sum), name, count)
+	}
+	histogram("packtrack_request_duration_seconds", "Ingest HTTP attempt latency.", p.latencyCounts, p.latencySum, p.latencyCount)
+	histogram("packtrack_flush_duration_seconds", "AsyncClient batch send time, including retries.", p.flushCounts, p.flushSum, p.flushCount)
+}
+
+func formatFloat(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
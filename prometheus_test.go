@@ -0,0 +1,111 @@
+package packtrack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	var calls atomic.Int32
+	var lastLen atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		lastLen.Store(int64(len(b)))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	pm := NewPrometheusMetrics()
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithCompression(CompressionGzip),
+		WithRetry(2, time.Millisecond, time.Millisecond, 0), WithMetricsHooks(pm.Hooks()),
+		WithMinSeverity(SeverityInfo))
+	ac, _ := NewAsyncClient(c, WithBatchSize(100), WithFlushInterval(0))
+	for range 3 {
+		if err := ac.Enqueue(newTestEvent()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	debug := newTestEvent()
+	debug.Severity = SeverityDebug
+	_ = ac.Enqueue(debug)
+	if err := ac.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Enqueue(newTestEvent()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("enqueue after close = %v", err)
+	}
+	if lastLen.Load() == 0 {
+		t.Fatal("retry sent an empty body")
+	}
+
+	rec := httptest.NewRecorder()
+	pm.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %q", ct)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE packtrack_events_enqueued_total counter\npacktrack_events_enqueued_total 3\n",
+		`packtrack_events_dropped_total{reason="severity"} 1`,
+		"packtrack_events_sent_total 3\n",
+		"packtrack_batches_sent_total 1\n",
+		`packtrack_retries_total{reason="server_error"} 1`,
+		`packtrack_requests_total{code="2xx"} 1`,
+		`packtrack_requests_total{code="5xx"} 1`,
+		`packtrack_request_duration_seconds_bucket{le="+Inf"} 2`,
+		"packtrack_request_duration_seconds_count 2\n",
+		"# TYPE packtrack_queue_depth gauge\npacktrack_queue_depth 0\n",
+		"# TYPE packtrack_backoff gauge\npacktrack_backoff 0\n",
+		`packtrack_enqueue_rejected_total{reason="closed"} 1`,
+		"packtrack_flush_duration_seconds_count 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "packtrack_payload_compressed_bytes_total 0\n") ||
+		strings.Contains(out, "packtrack_payload_uncompressed_bytes_total 0\n") {
+		t.Errorf("payload bytes not recorded:\n%s", out)
+	}
+}
+
+func TestPrometheusMetrics_Backoff(t *testing.T) {
+	pm := NewPrometheusMetrics()
+	h := pm.Hooks()
+	gauge := func() string {
+		var b strings.Builder
+		pm.write(&b)
+		_, v, _ := strings.Cut(b.String(), "\npacktrack_backoff ")
+		v, _, _ = strings.Cut(v, "\n")
+		return v
+	}
+	h.OnRetry(1, RetryReasonServerError)
+	if g := gauge(); g != "1" {
+		t.Errorf("after retry = %s", g)
+	}
+	h.OnIngestSuccess(1)
+	if g := gauge(); g != "0" {
+		t.Errorf("after success = %s", g)
+	}
+	h.OnIngestFailure(1)
+	if g := gauge(); g != "1" {
+		t.Errorf("after failure = %s", g)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel = %q", got)
+	}
+}
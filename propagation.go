@@ -0,0 +1,116 @@
+package packtrack
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Carrier keys used by Inject and Extract.
+const (
+	HeaderWorkflowID   = "X-PackTrack-Workflow-Id"
+	HeaderWorkflowName = "X-PackTrack-Workflow-Name"
+	HeaderRunID        = "X-PackTrack-Run-Id"
+	HeaderStepID       = "X-PackTrack-Step-Id"
+)
+
+type workflowCtxKey struct{}
+
+// ContextWithWorkflow returns a context carrying wf. IngestEvent and
+// IngestBatch fill empty Workflow fields of events from it.
+func ContextWithWorkflow(ctx context.Context, wf Workflow) context.Context {
+	return context.WithValue(ctx, workflowCtxKey{}, wf)
+}
+
+// WorkflowFromContext returns the workflow carried by ctx, including one set
+// by a Run or Step handle's Context.
+func WorkflowFromContext(ctx context.Context) (Workflow, bool) {
+	wf, ok := ctx.Value(workflowCtxKey{}).(Workflow)
+	return wf, ok
+}
+
+// fillWorkflow copies empty Workflow fields from the context workflow, unless
+// the event names a different workflow.
+func fillWorkflow(ctx context.Context, e *Event) {
+	wf, ok := WorkflowFromContext(ctx)
+	if !ok || (e.Workflow.ID != "" && e.Workflow.ID != wf.ID) {
+		return
+	}
+	if e.Workflow.ID == "" {
+		e.Workflow.ID = wf.ID
+	}
+	if e.Workflow.Name == "" {
+		e.Workflow.Name = wf.Name
+	}
+	if e.Workflow.RunID == "" {
+		e.Workflow.RunID = wf.RunID
+	}
+	if e.Workflow.StepID == "" {
+		e.Workflow.StepID = wf.StepID
+	}
+}
+
+// TextMapCarrier is a string key/value store that workflow context can be
+// injected into and extracted from, such as HTTP or queue message headers.
+type TextMapCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// HeaderCarrier adapts http.Header to TextMapCarrier.
+type HeaderCarrier http.Header
+
+func (h HeaderCarrier) Get(key string) string { return http.Header(h).Get(key) }
+func (h HeaderCarrier) Set(key, value string) { http.Header(h).Set(key, value) }
+
+// MapCarrier adapts map[string]string to TextMapCarrier. Get falls back to a
+// case-insensitive match, since some brokers lowercase header names.
+type MapCarrier map[string]string
+
+func (m MapCarrier) Get(key string) string {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func (m MapCarrier) Set(key, value string) { m[key] = value }
+
+// Inject writes the workflow carried by ctx into carrier. Empty fields are
+// not written.
+func Inject(ctx context.Context, carrier TextMapCarrier) {
+	wf, ok := WorkflowFromContext(ctx)
+	if !ok {
+		return
+	}
+	for _, kv := range [...]struct{ k, v string }{
+		{HeaderWorkflowID, wf.ID},
+		{HeaderWorkflowName, wf.Name},
+		{HeaderRunID, wf.RunID},
+		{HeaderStepID, wf.StepID},
+	} {
+		if kv.v != "" {
+			carrier.Set(kv.k, kv.v)
+		}
+	}
+}
+
+// Extract returns ctx carrying the workflow found in carrier. ctx is returned
+// unchanged when carrier has neither a workflow ID nor a run ID.
+func Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	wf := Workflow{
+		ID:     carrier.Get(HeaderWorkflowID),
+		Name:   carrier.Get(HeaderWorkflowName),
+		RunID:  carrier.Get(HeaderRunID),
+		StepID: carrier.Get(HeaderStepID),
+	}
+	if wf.ID == "" && wf.RunID == "" {
+		return ctx
+	}
+	return ContextWithWorkflow(ctx, wf)
+}
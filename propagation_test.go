@@ -0,0 +1,60 @@
+package packtrack
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestInjectExtract_Header(t *testing.T) {
+	wf := Workflow{ID: "wf", Name: "sync", RunID: "r1", StepID: "s1"}
+	h := http.Header{}
+	Inject(ContextWithWorkflow(context.Background(), wf), HeaderCarrier(h))
+	if h.Get(HeaderRunID) != "r1" {
+		t.Fatalf("missing run header: %v", h)
+	}
+	got, ok := WorkflowFromContext(Extract(context.Background(), HeaderCarrier(h)))
+	if !ok || got != wf {
+		t.Fatalf("extract = %+v, %v", got, ok)
+	}
+}
+
+func TestInjectExtract_MapCaseInsensitive(t *testing.T) {
+	m := MapCarrier{"x-packtrack-workflow-id": "wf", "x-packtrack-run-id": "r1"}
+	got, ok := WorkflowFromContext(Extract(context.Background(), m))
+	if !ok || got.ID != "wf" || got.RunID != "r1" {
+		t.Fatalf("extract = %+v, %v", got, ok)
+	}
+	ctx := Extract(context.Background(), MapCarrier{})
+	if _, ok := WorkflowFromContext(ctx); ok {
+		t.Fatalf("expected no workflow from empty carrier")
+	}
+}
+
+func TestIngestEvent_FillsWorkflowFromContext(t *testing.T) {
+	ts, events := newCaptureServer(t)
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"))
+	ctx := ContextWithWorkflow(context.Background(), Workflow{ID: "wf", RunID: "r1", StepID: "s1"})
+
+	e := newTestEvent()
+	e.Workflow = Workflow{}
+	other := newTestEvent()
+	other.Workflow = Workflow{ID: "other"}
+	_, _ = c.IngestBatch(ctx, []Event{e, other})
+
+	run := StartRun(ctx, ClientEmitter(c), Workflow{})
+	if wf := run.Workflow(); wf.ID != "wf" || wf.RunID == "r1" || wf.RunID == "" {
+		t.Fatalf("run should start its own run of the propagated workflow, got %+v", wf)
+	}
+
+	got := events()
+	if got[0].Workflow.ID != "wf" || got[0].Workflow.RunID != "r1" || got[0].Workflow.StepID != "s1" {
+		t.Fatalf("workflow not filled: %+v", got[0].Workflow)
+	}
+	if got[1].Workflow.RunID != "" {
+		t.Fatalf("different workflow must not inherit run id: %+v", got[1].Workflow)
+	}
+	if md := got[2].Metadata; md[MetaParentRunID] != "r1" || md[MetaParentWorkflowID] != "wf" || md[MetaParentStepID] != "s1" {
+		t.Fatalf("run start missing upstream links: %v", md)
+	}
+}
@@ -0,0 +1,258 @@
+package packtrack
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultRedactionMask replaces redacted values.
+const DefaultRedactionMask = "[REDACTED]"
+
+// ValueRule masks substrings of string values that match Pattern.
+type ValueRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Valid optionally confirms a match before it is masked, e.g. a Luhn
+	// check for card numbers. Nil masks every match.
+	Valid func(match string) bool
+}
+
+// RedactionConfig configures a Redactor.
+type RedactionConfig struct {
+	// Keys name map keys whose values are replaced entirely, whatever their
+	// type. Keys and map keys are split into lowercase segments at
+	// punctuation and camelCase boundaries, and a key matches when its
+	// segments appear as a run in the map key: "token" matches
+	// "access_token" and "X-Auth-Token" but not "max_tokens", and "api_key"
+	// matches "apiKey".
+	Keys []string
+	// Rules mask matching substrings inside string values and the message.
+	Rules []ValueRule
+	// Mask replaces redacted content. Defaults to DefaultRedactionMask.
+	Mask string
+}
+
+// DefaultRedactionConfig returns key patterns for common secret names and
+// value rules for emails, bearer tokens, card numbers, and API-key-like strings.
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Keys: []string{
+			"password", "passwd", "secret", "token", "authorization",
+			"api_key", "apikey", "cookie", "credential", "credentials", "private_key",
+		},
+		Rules: []ValueRule{
+			{Name: "email", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+			{Name: "bearer", Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)},
+			{Name: "credit_card", Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), Valid: luhnValid},
+			{Name: "api_key", Pattern: regexp.MustCompile(
+				`\b(?:(?:sk|pk|rk|pt)_(?:live|test)_[A-Za-z0-9]{8,}` + // Stripe/PackTrack style
+					`|sk-[A-Za-z0-9_\-]{20,}` + // OpenAI/Anthropic style
+					`|AKIA[0-9A-Z]{16}` + // AWS access key ID
+					`|gh[pousr]_[A-Za-z0-9]{36,}` + // GitHub tokens
+					`|xox[abpr]-[A-Za-z0-9\-]{10,})`)},
+		},
+		Mask: DefaultRedactionMask,
+	}
+}
+
+// Redactor scrubs secrets and PII from events. It is safe for concurrent use.
+type Redactor struct {
+	keys  [][]string // segments of each RedactionConfig.Keys entry
+	rules []ValueRule
+	mask  string
+}
+
+// NewRedactor returns a Redactor for cfg.
+func NewRedactor(cfg RedactionConfig) *Redactor {
+	r := &Redactor{rules: cfg.Rules, mask: cfg.Mask}
+	if r.mask == "" {
+		r.mask = DefaultRedactionMask
+	}
+	for _, k := range cfg.Keys {
+		if segs := keySegments(k); len(segs) > 0 {
+			r.keys = append(r.keys, segs)
+		}
+	}
+	return r
+}
+
+// Redact scrubs e.Message, e.Metadata, e.Extra, and e.Unknown, descending
+// into nested maps and slices. Nested containers are copied, never mutated in
+// place.
+func (r *Redactor) Redact(e *Event) {
+	e.Message = r.RedactString(e.Message)
+	if e.Metadata != nil {
+		e.Metadata = r.redactMap(e.Metadata)
+	}
+	if e.Extra != nil {
+		e.Extra = r.redactMap(e.Extra)
+	}
+	if e.Unknown != nil {
+		e.Unknown = r.redactRaw(e.Unknown)
+	}
+}
+
+// RedactString masks every value-rule match in s.
+func (r *Redactor) RedactString(s string) string {
+	for _, rule := range r.rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		if rule.Valid == nil {
+			s = rule.Pattern.ReplaceAllLiteralString(s, r.mask)
+			continue
+		}
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(m string) string {
+			if rule.Valid(m) {
+				return r.mask
+			}
+			return m
+		})
+	}
+	return s
+}
+
+// RedactValue returns a scrubbed copy of v, which may be a string, map, or
+// slice of such values. Other types are returned unchanged.
+func (r *Redactor) RedactValue(v any) any {
+	switch t := v.(type) {
+	case string:
+		return r.RedactString(t)
+	case map[string]any:
+		return r.redactMap(t)
+	case map[string]string:
+		m := make(map[string]string, len(t))
+		for k, s := range t {
+			if r.sensitiveKey(k) {
+				m[k] = r.mask
+			} else {
+				m[k] = r.RedactString(s)
+			}
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, x := range t {
+			s[i] = r.RedactValue(x)
+		}
+		return s
+	case []string:
+		s := make([]string, len(t))
+		for i, x := range t {
+			s[i] = r.RedactString(x)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) redactMap(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		if r.sensitiveKey(k) {
+			out[k] = r.mask
+		} else {
+			out[k] = r.RedactValue(v)
+		}
+	}
+	return out
+}
+
+// redactRaw scrubs JSON values kept verbatim, such as Event.Unknown. Values
+// that fail to decode are masked whole.
+func (r *Redactor) redactRaw(in map[string]json.RawMessage) map[string]json.RawMessage {
+	mask, _ := json.Marshal(r.mask)
+	out := make(map[string]json.RawMessage, len(in))
+	for k, raw := range in {
+		if r.sensitiveKey(k) {
+			out[k] = mask
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			out[k] = mask
+			continue
+		}
+		b, err := json.Marshal(r.RedactValue(v))
+		if err != nil {
+			out[k] = mask
+			continue
+		}
+		out[k] = b
+	}
+	return out
+}
+
+func (r *Redactor) sensitiveKey(k string) bool {
+	if len(r.keys) == 0 {
+		return false
+	}
+	segs := keySegments(k)
+	for _, p := range r.keys {
+		for i := 0; i+len(p) <= len(segs); i++ {
+			if slices.Equal(segs[i:i+len(p)], p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keySegments splits a key into lowercase words at non-alphanumeric
+// characters and camelCase boundaries: "X-APIKey" and "x_api_key" both give
+// [x api key].
+func keySegments(k string) []string {
+	var segs []string
+	var cur []rune
+	rs := []rune(k)
+	flush := func() {
+		if len(cur) > 0 {
+			segs = append(segs, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	for i, c := range rs {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(c) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, c)
+	}
+	flush()
+	return segs
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,85 @@
+package packtrack
+
+import (
+	"hash/fnv"
+	"maps"
+	"math/rand/v2"
+)
+
+// SampleRateKey is the Metadata key stamped on sampled events with the keep
+// rate that applied, so the backend can re-weight counts (weight = 1/rate).
+const SampleRateKey = "_sample_rate"
+
+// SamplingConfig configures a Sampler. Rates are keep fractions in (0,1]; in
+// every field 0 means unset and a negative rate keeps only errors. Workflow
+// rates take precedence over source rates, which take precedence over Rate;
+// an unset rate falls through to the next level. The zero SamplingConfig
+// keeps everything.
+type SamplingConfig struct {
+	Rate          float64            // default keep rate
+	SourceRates   map[string]float64 // keyed by Source.System
+	WorkflowRates map[string]float64 // keyed by Workflow.ID
+}
+
+// Sampler makes run-consistent keep/drop decisions. Decisions hash
+// Workflow.RunID, so every event of a run is either kept or dropped together.
+// Events with SeverityError or StatusError are always kept. Events without a
+// RunID are sampled independently.
+type Sampler struct {
+	cfg SamplingConfig
+}
+
+// NewSampler returns a Sampler for cfg.
+func NewSampler(cfg SamplingConfig) *Sampler { return &Sampler{cfg: cfg} }
+
+// Sample reports whether e is kept. Kept events sampled at a rate below 1 get
+// Metadata[SampleRateKey] set to that rate; the caller's Metadata map is not
+// mutated.
+func (s *Sampler) Sample(e *Event) bool {
+	if e.Severity == SeverityError || e.Status == StatusError {
+		return true
+	}
+	rate := s.rate(*e)
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	var x float64
+	if e.Workflow.RunID != "" {
+		x = hashUnit(e.Workflow.RunID)
+	} else {
+		x = rand.Float64()
+	}
+	if x >= rate {
+		return false
+	}
+	md := maps.Clone(e.Metadata)
+	if md == nil {
+		md = make(map[string]any, 1)
+	}
+	md[SampleRateKey] = rate
+	e.Metadata = md
+	return true
+}
+
+func (s *Sampler) rate(e Event) float64 {
+	if r := s.cfg.WorkflowRates[e.Workflow.ID]; r != 0 {
+		return r
+	}
+	if r := s.cfg.SourceRates[e.Source.System]; r != 0 {
+		return r
+	}
+	if s.cfg.Rate != 0 {
+		return s.cfg.Rate
+	}
+	return 1
+}
+
+// hashUnit maps s uniformly onto [0,1).
+func hashUnit(s string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,100 @@
+package packtrack
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSampler_RunConsistent(t *testing.T) {
+	s := NewSampler(SamplingConfig{Rate: 0.5})
+	kept := 0
+	const runs = 2000
+	for i := 0; i < runs; i++ {
+		run := fmt.Sprintf("run-%d", i)
+		var first bool
+		for j := 0; j < 5; j++ {
+			e := newTestEvent()
+			e.Status = StatusRunning
+			e.Workflow.RunID = run
+			got := s.Sample(&e)
+			if j == 0 {
+				first = got
+			} else if got != first {
+				t.Fatalf("run %s split across decisions", run)
+			}
+			if got && e.Metadata[SampleRateKey] != 0.5 {
+				t.Fatalf("expected sample rate stamp, got %v", e.Metadata)
+			}
+		}
+		if first {
+			kept++
+		}
+	}
+	if frac := float64(kept) / runs; math.Abs(frac-0.5) > 0.05 {
+		t.Fatalf("kept fraction %.3f far from 0.5", frac)
+	}
+}
+
+func TestSampler_AlwaysKeepsErrors(t *testing.T) {
+	s := NewSampler(SamplingConfig{Rate: -1})
+	e := newTestEvent()
+	e.Severity = SeverityError
+	if !s.Sample(&e) {
+		t.Fatalf("error severity dropped")
+	}
+	e = newTestEvent()
+	e.Status = StatusError
+	if !s.Sample(&e) {
+		t.Fatalf("error status dropped")
+	}
+	if e.Metadata != nil {
+		t.Fatalf("unsampled event should not be stamped")
+	}
+	e = newTestEvent()
+	if s.Sample(&e) {
+		t.Fatalf("expected drop at negative rate")
+	}
+}
+
+func TestSampler_ZeroConfigKeepsAll(t *testing.T) {
+	s := NewSampler(SamplingConfig{WorkflowRates: map[string]float64{"quiet": 0}})
+	e := newTestEvent()
+	if !s.Sample(&e) || e.Metadata != nil {
+		t.Fatalf("unset rate dropped or stamped %+v", e)
+	}
+	e.Workflow.ID = "quiet"
+	if !s.Sample(&e) || e.Metadata != nil {
+		t.Fatalf("workflow rate 0 should behave like Rate 0 %+v", e)
+	}
+}
+
+func TestSampler_ZeroOverrideFallsThrough(t *testing.T) {
+	s := NewSampler(SamplingConfig{
+		Rate:          -1,
+		SourceRates:   map[string]float64{"test": 0},
+		WorkflowRates: map[string]float64{"quiet": 0},
+	})
+	e := newTestEvent()
+	e.Workflow.ID = "quiet"
+	if s.Sample(&e) {
+		t.Fatalf("zero overrides should fall through to the negative default rate")
+	}
+}
+
+func TestSampler_Overrides(t *testing.T) {
+	s := NewSampler(SamplingConfig{
+		Rate:          -1,
+		SourceRates:   map[string]float64{"test": 1},
+		WorkflowRates: map[string]float64{"noisy": -1},
+	})
+	e := newTestEvent()
+	if !s.Sample(&e) || e.Metadata != nil {
+		t.Fatalf("source rate 1 should keep without stamp")
+	}
+	e = newTestEvent()
+	e.Workflow.ID = "noisy"
+	if s.Sample(&e) {
+		t.Fatalf("workflow rate should override source rate")
+	}
+}
@@ -0,0 +1,90 @@
+package packtrack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the event severity level. Levels are ordered, so thresholds can
+// be expressed with comparisons such as e.Severity >= SeverityWarn. The zero
+// value means unset and ranks below SeverityDebug.
+//
+// Severity encodes as its lowercase name in JSON and other text formats.
+type Severity int
+
+// SeverityUnknown is what decoding yields for a severity name this version
+// does not know, such as "fatal" from a newer producer. It ranks below every
+// level, is not Valid, and encodes as "unknown"; Event keeps the original
+// text and writes it back when the event is re-encoded.
+const SeverityUnknown Severity = -1
+
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+)
+
+var severityNames = [...]string{
+	SeverityDebug: "debug",
+	SeverityInfo:  "info",
+	SeverityWarn:  "warn",
+	SeverityError: "error",
+}
+
+// ParseSeverity parses a severity name. Matching is case-insensitive and
+// accepts "warning" for SeverityWarn.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return SeverityDebug, nil
+	case "info":
+		return SeverityInfo, nil
+	case "warn", "warning":
+		return SeverityWarn, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return 0, fmt.Errorf("invalid severity %q, want debug|info|warn|error", s)
+}
+
+// Valid reports whether s is one of the defined levels.
+func (s Severity) Valid() bool { return s >= SeverityDebug && s <= SeverityError }
+
+func (s Severity) String() string {
+	if s.Valid() {
+		return severityNames[s]
+	}
+	switch s {
+	case 0:
+		return ""
+	case SeverityUnknown:
+		return "unknown"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler. An unset severity encodes as
+// the empty string.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s == 0 || s == SeverityUnknown || s.Valid() {
+		return []byte(s.String()), nil
+	}
+	return nil, fmt.Errorf("invalid severity %d", int(s))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The empty string decodes
+// to the unset severity and unrecognized names to SeverityUnknown, so one
+// unexpected value does not fail decoding of a whole event.
+func (s *Severity) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*s = 0
+		return nil
+	}
+	v, err := ParseSeverity(string(b))
+	if err != nil {
+		v = SeverityUnknown
+	}
+	*s = v
+	return nil
+}
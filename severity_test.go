@@ -0,0 +1,131 @@
+package packtrack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseSeverity(t *testing.T) {
+	cases := map[string]Severity{"debug": SeverityDebug, "INFO": SeverityInfo, "warning": SeverityWarn, " error ": SeverityError}
+	for in, want := range cases {
+		got, err := ParseSeverity(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseSeverity(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseSeverity("loud"); err == nil {
+		t.Fatalf("expected error for unknown severity")
+	}
+	if !(SeverityDebug < SeverityInfo && SeverityInfo < SeverityWarn && SeverityWarn < SeverityError) {
+		t.Fatalf("severities not ordered")
+	}
+}
+
+func TestSeverity_JSON(t *testing.T) {
+	b, err := json.Marshal(map[string]Severity{"s": SeverityWarn})
+	if err != nil || string(b) != `{"s":"warn"}` {
+		t.Fatalf("marshal: %s %v", b, err)
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(`{"severity":"error"}`), &e); err != nil || e.Severity != SeverityError {
+		t.Fatalf("unmarshal: %v %v", e.Severity, err)
+	}
+	if err := json.Unmarshal([]byte(`{"severity":"fatal","message":"m"}`), &e); err != nil ||
+		e.Severity != SeverityUnknown || e.Message != "m" {
+		t.Fatalf("unknown severity: %v %+v", err, e)
+	}
+	if b, _ := json.Marshal(map[string]Severity{"s": SeverityUnknown}); string(b) != `{"s":"unknown"}` {
+		t.Fatalf("marshal unknown: %s", b)
+	}
+	if err := e.Validate(); err == nil || !strings.Contains(err.Error(), "severity") {
+		t.Fatalf("validate unknown severity: %v", err)
+	}
+	if b, err := json.Marshal(e); err != nil || !strings.Contains(string(b), `"severity":"fatal"`) {
+		t.Fatalf("relay unknown severity: %s %v", b, err)
+	}
+	e.Severity = SeverityWarn
+	if b, _ := json.Marshal(e); !strings.Contains(string(b), `"severity":"warn"`) {
+		t.Fatalf("overwritten severity: %s", b)
+	}
+}
+
+func TestMinSeverity_Overrides(t *testing.T) {
+	var sent, dropped int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evs []Event
+		_ = json.NewDecoder(r.Body).Decode(&evs)
+		atomic.AddInt32(&sent, int32(len(evs)))
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	hooks := &MetricsHooks{OnDropped: func(reason string, n int) {
+		if reason == DropReasonSeverity {
+			atomic.AddInt32(&dropped, int32(n))
+		}
+	}}
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithMetricsHooks(hooks),
+		WithMinSeverity(SeverityWarn),
+		WithSourceMinSeverity("chatty", SeverityError),
+		WithWorkflowMinSeverity("debug-wf", SeverityDebug),
+	)
+	mk := func(sev Severity, system, wf string) Event {
+		e := newTestEvent()
+		e.Severity, e.Source.System, e.Workflow.ID = sev, system, wf
+		return e
+	}
+	batch := []Event{
+		mk(SeverityInfo, "test", "wf"),          // dropped: global warn
+		mk(SeverityWarn, "test", "wf"),          // kept
+		mk(SeverityWarn, "chatty", "wf"),        // dropped: source error
+		mk(SeverityDebug, "chatty", "debug-wf"), // kept: workflow override wins
+	}
+	if _, err := c.IngestBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 || dropped != 2 {
+		t.Fatalf("sent=%d dropped=%d", sent, dropped)
+	}
+	// A fully filtered single event is not sent.
+	if _, err := c.IngestEvent(context.Background(), mk(SeverityDebug, "test", "wf")); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 || dropped != 3 {
+		t.Fatalf("sent=%d dropped=%d", sent, dropped)
+	}
+}
+
+func TestMinSeverity_AsyncFiltersAtEnqueue(t *testing.T) {
+	c, _ := NewClient(WithBaseURL("http://example"), WithAPIKey("k"), WithMinSeverity(SeverityInfo))
+	ac, _ := NewAsyncClient(c, WithQueueCapacity(1), WithFlushInterval(time.Hour))
+	defer ac.Close(context.Background())
+	debug := newTestEvent()
+	debug.Severity = SeverityDebug
+	for i := 0; i < 3; i++ {
+		if err := ac.Enqueue(debug); err != nil {
+			t.Fatalf("filtered event should not occupy the queue: %v", err)
+		}
+	}
+}
+
+func TestMinSeverity_UnsetKeepsUnknown(t *testing.T) {
+	ts, got := newCaptureServer(t)
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"))
+	var e Event
+	if err := json.Unmarshal([]byte(`{"severity":"fatal"}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	ev := newTestEvent()
+	ev.Severity, ev.Unknown = e.Severity, e.Unknown
+	if _, err := c.IngestEvent(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+	if evs := got(); len(evs) != 1 || evs[0].Severity != SeverityUnknown || string(evs[0].Unknown["severity"]) != `"fatal"` {
+		t.Fatalf("unknown severity should be kept and relayed: %+v", evs)
+	}
+}
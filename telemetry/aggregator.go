@@ -0,0 +1,349 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// String returns "gauge", "counter", or "histogram".
+func (t Type) String() string {
+	switch t {
+	case Gauge:
+		return "gauge"
+	case Counter:
+		return "counter"
+	case Histogram:
+		return "histogram"
+	}
+	return "unknown"
+}
+
+// DefaultBuckets are histogram upper bounds suited to latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Bucket is one histogram bucket. Count is the number of observations in
+// (previous UpperBound, UpperBound]; the last bucket's UpperBound is +Inf.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Aggregate is one series (name and label set) aggregated over a flush
+// interval. Counters report the interval's sum in Value, gauges the last
+// value recorded, which is reported again every interval until replaced.
+// Histograms report Count, Sum, Min, Max, and Buckets.
+type Aggregate struct {
+	Name   string
+	Type   Type
+	Unit   string
+	Labels Labels
+	Start  time.Time
+	End    time.Time
+
+	Value float64
+
+	Count   uint64
+	Sum     float64
+	Min     float64
+	Max     float64
+	Buckets []Bucket
+	// Sketch holds the interval's observations for quantile queries. Nil
+	// when sketches are disabled.
+	Sketch *Sketch
+}
+
+// ExportFunc receives the aggregates of one flush interval.
+type ExportFunc func(ctx context.Context, aggs []Aggregate) error
+
+// AggregatorOption configures NewAggregator.
+type AggregatorOption func(*aggregatorConfig)
+
+type aggregatorConfig struct {
+	interval time.Duration
+	shards   int
+	onError  func(error)
+	buckets  map[string][]float64
+	accuracy float64
+}
+
+// WithFlushInterval sets how often aggregates are exported. Defaults to 10s;
+// zero or negative disables the background flush.
+func WithFlushInterval(d time.Duration) AggregatorOption {
+	return func(c *aggregatorConfig) { c.interval = d }
+}
+
+// WithShards sets the number of lock stripes, rounded up to a power of two.
+// Defaults to 16.
+func WithShards(n int) AggregatorOption { return func(c *aggregatorConfig) { c.shards = n } }
+
+// WithHistogramBuckets sets the bucket upper bounds for histograms named
+// name, overriding DefaultBuckets. Bounds must be finite and strictly
+// increasing; otherwise Record returns an error for that histogram.
+func WithHistogramBuckets(name string, bounds []float64) AggregatorOption {
+	return func(c *aggregatorConfig) {
+		if c.buckets == nil {
+			c.buckets = make(map[string][]float64)
+		}
+		c.buckets[name] = slices.Clone(bounds)
+	}
+}
+
+// WithSketchAccuracy sets the relative accuracy of the quantile Sketch kept
+// for each histogram. Defaults to DefaultRelativeAccuracy; zero disables
+// sketches.
+func WithSketchAccuracy(alpha float64) AggregatorOption {
+	return func(c *aggregatorConfig) { c.accuracy = alpha }
+}
+
+// WithExportErrorHandler receives errors from background flushes.
+func WithExportErrorHandler(fn func(error)) AggregatorOption {
+	return func(c *aggregatorConfig) { c.onError = fn }
+}
+
+// Aggregator is a Recorder that aggregates metrics in process per name and
+// label set and periodically exports them. Series are reset after each
+// flush, so counters are exported as per-interval deltas. Recording is
+// lock-striped across shards and safe for concurrent use.
+type Aggregator struct {
+	export ExportFunc
+	cfg    aggregatorConfig
+	shards []shard
+	mask   uint32
+
+	flushMu   sync.Mutex // serializes flushes
+	lastFlush time.Time
+
+	histErr map[string]error // invalid WithHistogramBuckets bounds
+	skErr   error            // invalid WithSketchAccuracy
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type shard struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	name   string
+	typ    Type
+	unit   string
+	labels Labels
+	value  float64
+	hist   *BucketHistogram
+	sketch *Sketch
+}
+
+// NewAggregator returns an Aggregator exporting through export and starts
+// its background flush.
+func NewAggregator(export ExportFunc, opts ...AggregatorOption) *Aggregator {
+	cfg := aggregatorConfig{interval: 10 * time.Second, shards: 16, accuracy: DefaultRelativeAccuracy}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	n := 1
+	for n < cfg.shards {
+		n <<= 1
+	}
+	a := &Aggregator{
+		export:    export,
+		cfg:       cfg,
+		shards:    make([]shard, n),
+		mask:      uint32(n - 1),
+		lastFlush: time.Now(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for i := range a.shards {
+		a.shards[i].series = make(map[string]*series)
+	}
+	for name, bounds := range cfg.buckets {
+		if err := validateBounds(bounds); err != nil {
+			if a.histErr == nil {
+				a.histErr = make(map[string]error)
+			}
+			a.histErr[name] = fmt.Errorf("histogram %q: %w", name, err)
+		}
+	}
+	if cfg.accuracy != 0 {
+		_, a.skErr = NewSketch(cfg.accuracy)
+	}
+	if cfg.interval > 0 {
+		go a.loop()
+	} else {
+		close(a.done)
+	}
+	return a
+}
+
+// Record adds m to its series. m.Time is ignored; aggregates are stamped
+// with their flush interval.
+func (a *Aggregator) Record(_ context.Context, m Metric) error {
+	if m.Name == "" {
+		return errors.New("metric name is empty")
+	}
+	if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+		return fmt.Errorf("metric value %v is not finite", m.Value)
+	}
+	if m.Type == Histogram {
+		if err := a.histErr[m.Name]; err != nil {
+			return err
+		}
+		if a.skErr != nil {
+			return a.skErr
+		}
+	}
+	key := seriesKey(m.Name, m.Type, m.Labels)
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	sh := &a.shards[h.Sum32()&a.mask]
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	s := sh.series[key]
+	if s == nil {
+		s = &series{name: m.Name, typ: m.Type, unit: m.Unit, labels: cloneLabels(m.Labels)}
+		if m.Type == Histogram {
+			bounds, ok := a.cfg.buckets[m.Name]
+			if !ok {
+				bounds = DefaultBuckets
+			}
+			s.hist = newBucketHistogram(bounds)
+			if a.cfg.accuracy != 0 {
+				s.sketch, _ = NewSketch(a.cfg.accuracy)
+			}
+		}
+		sh.series[key] = s
+	}
+	switch m.Type {
+	case Counter:
+		s.value += m.Value
+	case Histogram:
+		s.hist.Observe(m.Value)
+		if s.sketch != nil {
+			s.sketch.Add(m.Value)
+		}
+	default:
+		s.value = m.Value
+	}
+	return nil
+}
+
+// Flush exports the aggregates recorded since the previous flush. Counter and
+// histogram series are reset; gauge series keep their last value.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+	start, end := a.lastFlush, time.Now()
+	a.lastFlush = end
+
+	var all []*series
+	for i := range a.shards {
+		sh := &a.shards[i]
+		sh.mu.Lock()
+		for k, s := range sh.series {
+			if s.typ == Gauge {
+				snap := *s
+				all = append(all, &snap)
+				continue
+			}
+			all = append(all, s)
+			delete(sh.series, k)
+		}
+		sh.mu.Unlock()
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	aggs := make([]Aggregate, 0, len(all))
+	for _, s := range all {
+		agg := Aggregate{Name: s.name, Type: s.typ, Unit: s.unit, Labels: s.labels, Start: start, End: end, Value: s.value}
+		if s.hist != nil {
+			s.hist.fill(&agg)
+			agg.Sketch = s.sketch
+		}
+		aggs = append(aggs, agg)
+	}
+	sort.Slice(aggs, func(i, j int) bool {
+		if aggs[i].Name != aggs[j].Name {
+			return aggs[i].Name < aggs[j].Name
+		}
+		return labelString(aggs[i].Labels) < labelString(aggs[j].Labels)
+	})
+	return a.export(ctx, aggs)
+}
+
+// Close stops the background flush and flushes what remains.
+func (a *Aggregator) Close(ctx context.Context) error {
+	a.once.Do(func() { close(a.stop) })
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return a.Flush(ctx)
+}
+
+func (a *Aggregator) loop() {
+	defer close(a.done)
+	t := time.NewTicker(a.cfg.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := a.Flush(context.Background()); err != nil && a.cfg.onError != nil {
+				a.cfg.onError(err)
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+func seriesKey(name string, typ Type, labels Labels) string {
+	return name + "\x00" + typ.String() + "\x00" + labelString(labels)
+}
+
+// labelString renders labels in sorted key order.
+func labelString(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte('\x00')
+	}
+	return b.String()
+}
+
+func cloneLabels(l Labels) Labels {
+	if l == nil {
+		return nil
+	}
+	out := make(Labels, len(l))
+	for k, v := range l {
+		out[k] = v
+	}
+	return out
+}
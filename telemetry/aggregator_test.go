@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+	"github.com/commandant-labs/pack-track-sdk/internal/packtracktest"
+)
+
+func TestAggregator(t *testing.T) {
+	var got []Aggregate
+	a := NewAggregator(func(_ context.Context, aggs []Aggregate) error {
+		got = append(got, aggs...)
+		return nil
+	}, WithFlushInterval(0), WithShards(4))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_ = a.Record(ctx, Metric{Name: "requests_total", Type: Counter, Value: 1, Labels: Labels{"route": "/a"}})
+			}
+			_ = a.Record(ctx, Metric{Name: "requests_total", Type: Counter, Value: 1, Labels: Labels{"route": "/b"}})
+			_ = a.Record(ctx, Metric{Name: "latency_seconds", Type: Histogram, Value: float64(i) * 0.1, Unit: "seconds"})
+		}()
+	}
+	wg.Wait()
+	_ = a.Record(ctx, Metric{Name: "queue_depth", Type: Gauge, Value: 3})
+	_ = a.Record(ctx, Metric{Name: "queue_depth", Type: Gauge, Value: 7})
+	if err := a.Record(ctx, Metric{Type: Counter, Value: 1}); err == nil {
+		t.Error("expected error for empty name")
+	}
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if err := a.Record(ctx, Metric{Name: "bad", Type: Gauge, Value: v}); err == nil {
+			t.Errorf("expected error for value %v", v)
+		}
+	}
+
+	if err := a.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d aggregates: %+v", len(got), got)
+	}
+	hist, gauge, reqA, reqB := got[0], got[1], got[2], got[3]
+	if reqA.Labels["route"] != "/a" || reqA.Value != 800 || reqB.Value != 8 {
+		t.Errorf("counters = %+v, %+v", reqA, reqB)
+	}
+	if gauge.Name != "queue_depth" || gauge.Value != 7 {
+		t.Errorf("gauge = %+v", gauge)
+	}
+	if hist.Count != 8 || hist.Min != 0 || math.Abs(hist.Max-0.7) > 1e-9 || math.Abs(hist.Sum-2.8) > 1e-9 {
+		t.Errorf("histogram = %+v", hist)
+	}
+	var n uint64
+	for _, b := range hist.Buckets {
+		n += b.Count
+	}
+	if n != 8 || !math.IsInf(hist.Buckets[len(hist.Buckets)-1].UpperBound, 1) {
+		t.Errorf("buckets = %+v", hist.Buckets)
+	}
+
+	// Counters and histograms reset after a flush; gauges keep their value.
+	got = nil
+	_ = a.Flush(ctx)
+	if len(got) != 1 || got[0].Name != "queue_depth" || got[0].Value != 7 {
+		t.Errorf("after flush: %+v", got)
+	}
+}
+
+func TestAggregatorBackgroundFlush(t *testing.T) {
+	flushed := make(chan []Aggregate, 1)
+	a := NewAggregator(func(_ context.Context, aggs []Aggregate) error {
+		select {
+		case flushed <- aggs:
+		default: // the gauge is re-exported every interval
+		}
+		return nil
+	}, WithFlushInterval(10*time.Millisecond))
+	defer a.Close(context.Background())
+	_ = a.Record(context.Background(), Metric{Name: "up", Type: Gauge, Value: 1})
+	select {
+	case aggs := <-flushed:
+		if len(aggs) != 1 || aggs[0].Name != "up" || !aggs[0].End.After(aggs[0].Start) {
+			t.Errorf("aggs = %+v", aggs)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no background flush")
+	}
+}
+
+func TestEventExporter(t *testing.T) {
+	fake := &packtracktest.AsyncClient{}
+	a := NewAggregator(EventExporter(fake, packtrack.Workflow{ID: "metrics"}), WithFlushInterval(0))
+	_ = a.Record(context.Background(), Metric{Name: "latency_seconds", Type: Histogram, Value: 0.2, Unit: "seconds", Labels: Labels{"tool": "search"}})
+	_ = a.Record(context.Background(), Metric{Name: "runs_total", Type: Counter, Value: 2})
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	events := fake.Events()
+	if len(events) != 2 {
+		t.Fatalf("got %d events", len(events))
+	}
+	h := events[0]
+	if h.Workflow.ID != "metrics" || h.Metadata[MetaMetricType] != "histogram" || h.Metadata[MetaMetricUnit] != "seconds" ||
+		h.Metadata[MetaMetricCount] != uint64(1) {
+		t.Errorf("histogram event = %+v", h)
+	}
+	if _, err := json.Marshal(h); err != nil {
+		t.Errorf("histogram event not encodable: %v", err)
+	}
+	if c := events[1]; c.Metadata[MetaMetricValue] != 2.0 || c.Metadata[MetaMetricType] != "counter" {
+		t.Errorf("counter event = %+v", c)
+	}
+}
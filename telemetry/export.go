@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+
+	packtrack "github.com/commandant-labs/pack-track-sdk"
+)
+
+// Metadata keys set on metric events by EventExporter.
+const (
+	MetaMetricName       = "metric.name"
+	MetaMetricType       = "metric.type"
+	MetaMetricUnit       = "metric.unit"
+	MetaMetricLabels     = "metric.labels"
+	MetaMetricValue      = "metric.value"
+	MetaMetricCount      = "metric.count"
+	MetaMetricSum        = "metric.sum"
+	MetaMetricMin        = "metric.min"
+	MetaMetricMax        = "metric.max"
+	MetaMetricBuckets    = "metric.buckets" // [{"le": "0.5", "count": 3}, ...]; the last "le" is "+Inf"
+	MetaMetricIntervalMS = "metric.interval_ms"
+	MetaMetricP50        = "metric.p50" // histogram quantiles, when a Sketch is kept
+	MetaMetricP90        = "metric.p90"
+	MetaMetricP99        = "metric.p99"
+)
+
+// EventExporter returns an ExportFunc that enqueues one info event per
+// aggregate on client, attributed to wf.
+func EventExporter(client packtrack.AsyncClient, wf packtrack.Workflow) ExportFunc {
+	return func(_ context.Context, aggs []Aggregate) error {
+		var errs []error
+		for _, agg := range aggs {
+			if err := client.Enqueue(MetricEvent(agg, wf)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// MetricEvent converts agg into a PackTrack event.
+func MetricEvent(agg Aggregate, wf packtrack.Workflow) packtrack.Event {
+	md := map[string]any{
+		MetaMetricName:       agg.Name,
+		MetaMetricType:       agg.Type.String(),
+		MetaMetricIntervalMS: agg.End.Sub(agg.Start).Milliseconds(),
+	}
+	if agg.Unit != "" {
+		md[MetaMetricUnit] = agg.Unit
+	}
+	if len(agg.Labels) > 0 {
+		labels := make(map[string]any, len(agg.Labels))
+		for k, v := range agg.Labels {
+			labels[k] = v
+		}
+		md[MetaMetricLabels] = labels
+	}
+	if agg.Type == Histogram {
+		md[MetaMetricCount] = agg.Count
+		md[MetaMetricSum] = agg.Sum
+		md[MetaMetricMin] = agg.Min
+		md[MetaMetricMax] = agg.Max
+		buckets := make([]any, len(agg.Buckets))
+		for i, b := range agg.Buckets {
+			buckets[i] = map[string]any{"le": formatBound(b.UpperBound), "count": b.Count}
+		}
+		md[MetaMetricBuckets] = buckets
+		if sk := agg.Sketch; sk != nil && sk.Count() > 0 {
+			md[MetaMetricP50] = sk.Quantile(0.5)
+			md[MetaMetricP90] = sk.Quantile(0.9)
+			md[MetaMetricP99] = sk.Quantile(0.99)
+		}
+	} else {
+		md[MetaMetricValue] = agg.Value
+	}
+	return packtrack.Event{
+		Timestamp: agg.End.UTC(),
+		Workflow:  wf,
+		Severity:  packtrack.SeverityInfo,
+		Status:    packtrack.StatusSuccess,
+		Message:   "metric " + agg.Name,
+		Metadata:  md,
+	}
+}
+
+// formatBound renders a bucket bound; JSON has no encoding for infinity.
+func formatBound(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,156 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// BucketHistogram counts observations into explicit buckets. It is not safe
+// for concurrent use.
+type BucketHistogram struct {
+	bounds   []float64
+	counts   []uint64 // len(bounds)+1; the last is the +Inf bucket
+	count    uint64
+	sum      float64
+	min, max float64
+}
+
+// NewBucketHistogram returns a histogram with the given finite, strictly
+// increasing upper bounds. An implicit +Inf bucket catches larger values.
+func NewBucketHistogram(bounds []float64) (*BucketHistogram, error) {
+	if err := validateBounds(bounds); err != nil {
+		return nil, err
+	}
+	return newBucketHistogram(slices.Clone(bounds)), nil
+}
+
+func newBucketHistogram(bounds []float64) *BucketHistogram {
+	return &BucketHistogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func validateBounds(bounds []float64) error {
+	if len(bounds) == 0 {
+		return errors.New("histogram needs at least one bucket bound")
+	}
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("histogram bound %v is not finite", b)
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("histogram bounds not strictly increasing at %v", b)
+		}
+	}
+	return nil
+}
+
+// LinearBuckets returns n bounds starting at start, width apart.
+func LinearBuckets(start, width float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = start + float64(i)*width
+	}
+	return out
+}
+
+// ExponentialBuckets returns n bounds starting at start, each factor times
+// the previous.
+func ExponentialBuckets(start, factor float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = start
+		start *= factor
+	}
+	return out
+}
+
+// Observe adds v.
+func (h *BucketHistogram) Observe(v float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, v)]++
+	if h.count == 0 || v < h.min {
+		h.min = v
+	}
+	if h.count == 0 || v > h.max {
+		h.max = v
+	}
+	h.count++
+	h.sum += v
+}
+
+// Merge adds o's observations. Both histograms must have the same bounds.
+func (h *BucketHistogram) Merge(o *BucketHistogram) error {
+	if !slices.Equal(h.bounds, o.bounds) {
+		return errors.New("cannot merge histograms with different bounds")
+	}
+	if o.count == 0 {
+		return nil
+	}
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	if h.count == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	if h.count == 0 || o.max > h.max {
+		h.max = o.max
+	}
+	h.count += o.count
+	h.sum += o.sum
+	return nil
+}
+
+func (h *BucketHistogram) Count() uint64 { return h.count }
+func (h *BucketHistogram) Sum() float64  { return h.sum }
+
+// Min returns the smallest observation, or 0 when empty.
+func (h *BucketHistogram) Min() float64 { return h.min }
+
+// Max returns the largest observation, or 0 when empty.
+func (h *BucketHistogram) Max() float64 { return h.max }
+
+// Buckets returns per-bucket counts; the last bucket's UpperBound is +Inf.
+func (h *BucketHistogram) Buckets() []Bucket {
+	out := make([]Bucket, len(h.counts))
+	for i, c := range h.counts {
+		ub := math.Inf(1)
+		if i < len(h.bounds) {
+			ub = h.bounds[i]
+		}
+		out[i] = Bucket{UpperBound: ub, Count: c}
+	}
+	return out
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) by linear interpolation
+// within the bucket holding it, clamped to the observed min and max. Its
+// error is bounded by the bucket width; use a Sketch for a relative error
+// guarantee. It returns NaN when empty or q is out of range.
+func (h *BucketHistogram) Quantile(q float64) float64 {
+	if h.count == 0 || q < 0 || q > 1 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	rank := q * float64(h.count)
+	var cum float64
+	for i, c := range h.counts {
+		if c == 0 || cum+float64(c) < rank {
+			cum += float64(c)
+			continue
+		}
+		lo, hi := h.min, h.max
+		if i > 0 {
+			lo = max(lo, h.bounds[i-1])
+		}
+		if i < len(h.bounds) {
+			hi = min(hi, h.bounds[i])
+		}
+		return lo + (hi-lo)*(rank-cum)/float64(c)
+	}
+	return h.max
+}
+
+func (h *BucketHistogram) fill(agg *Aggregate) {
+	agg.Count, agg.Sum, agg.Min, agg.Max = h.count, h.sum, h.min, h.max
+	agg.Buckets = h.Buckets()
+}
@@ -0,0 +1,196 @@
+package telemetry
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// DefaultRelativeAccuracy is the Sketch accuracy used by the Aggregator.
+const DefaultRelativeAccuracy = 0.01
+
+// defaultMaxBins bounds Sketch memory. With 1% accuracy, 2048 bins span
+// about 17 orders of magnitude before the lowest bins are collapsed.
+const defaultMaxBins = 2048
+
+// minIndexable is the smallest magnitude given its own bin; smaller values
+// count as zero.
+const minIndexable = 1e-9
+
+// Sketch is a mergeable quantile sketch in the style of DDSketch. Values are
+// counted in logarithmically sized bins, so that for any q the estimate x̂
+// returned by Quantile(q) is within the relative accuracy α of the exact
+// q-quantile x of the recorded values:
+//
+//	|x̂ - x| <= α·|x|
+//
+// where x is the value of rank floor(q·(n-1)) in sorted order. The bound holds
+// for values of magnitude at least 1e-9 (smaller ones are treated as zero) as
+// long as no more than 2048 bins per sign are needed; beyond that the lowest
+// bins are collapsed and only the quantiles they cover lose the guarantee.
+// Sketches with the same accuracy merge exactly. A Sketch is not safe for
+// concurrent use.
+type Sketch struct {
+	alpha    float64
+	gamma    float64
+	logGamma float64
+
+	pos, neg map[int]uint64 // bin index → count, by magnitude
+	zero     uint64
+	count    uint64
+	sum      float64
+	min, max float64
+}
+
+// NewSketch returns a Sketch with the given relative accuracy, 0 < α < 1.
+func NewSketch(relativeAccuracy float64) (*Sketch, error) {
+	if !(relativeAccuracy > 0 && relativeAccuracy < 1) {
+		return nil, errors.New("sketch relative accuracy must be in (0, 1)")
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &Sketch{
+		alpha:    relativeAccuracy,
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		pos:      make(map[int]uint64),
+		neg:      make(map[int]uint64),
+	}, nil
+}
+
+// RelativeAccuracy returns α.
+func (s *Sketch) RelativeAccuracy() float64 { return s.alpha }
+
+// Add records v. NaN and infinite values are ignored.
+func (s *Sketch) Add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	switch {
+	case v >= minIndexable:
+		s.pos[s.index(v)]++
+		collapse(s.pos)
+	case v <= -minIndexable:
+		s.neg[s.index(-v)]++
+		collapse(s.neg)
+	default:
+		s.zero++
+	}
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+	s.sum += v
+}
+
+// Merge adds o's values. Both sketches must have the same accuracy.
+func (s *Sketch) Merge(o *Sketch) error {
+	if s.alpha != o.alpha {
+		return errors.New("cannot merge sketches with different accuracy")
+	}
+	if o.count == 0 {
+		return nil
+	}
+	for i, c := range o.pos {
+		s.pos[i] += c
+	}
+	for i, c := range o.neg {
+		s.neg[i] += c
+	}
+	collapse(s.pos)
+	collapse(s.neg)
+	s.zero += o.zero
+	if s.count == 0 || o.min < s.min {
+		s.min = o.min
+	}
+	if s.count == 0 || o.max > s.max {
+		s.max = o.max
+	}
+	s.count += o.count
+	s.sum += o.sum
+	return nil
+}
+
+func (s *Sketch) Count() uint64 { return s.count }
+func (s *Sketch) Sum() float64  { return s.sum }
+func (s *Sketch) Min() float64  { return s.min }
+func (s *Sketch) Max() float64  { return s.max }
+
+// Quantile returns the estimated q-quantile, 0 <= q <= 1, or NaN when the
+// sketch is empty or q is out of range. Quantiles 0 and 1 are the exact
+// minimum and maximum.
+func (s *Sketch) Quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	switch q {
+	case 0:
+		return s.min
+	case 1:
+		return s.max
+	}
+	rank := q * float64(s.count-1)
+	var cum float64
+	v := s.max
+	found := false
+	// Ascending order: negatives by decreasing magnitude, zeros, positives.
+	for _, i := range sortedKeys(s.neg, true) {
+		if cum += float64(s.neg[i]); cum > rank {
+			v, found = -s.value(i), true
+			break
+		}
+	}
+	if !found {
+		if cum += float64(s.zero); cum > rank {
+			v, found = 0, true
+		}
+	}
+	if !found {
+		for _, i := range sortedKeys(s.pos, false) {
+			if cum += float64(s.pos[i]); cum > rank {
+				v = s.value(i)
+				break
+			}
+		}
+	}
+	return min(max(v, s.min), s.max)
+}
+
+// index returns the bin of magnitude v: bin i covers (γ^(i-1), γ^i].
+func (s *Sketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+// value returns the representative of bin i, within α of every value in it.
+func (s *Sketch) value(i int) float64 {
+	return 2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1)
+}
+
+// collapse merges the lowest bins until at most defaultMaxBins remain.
+func collapse(bins map[int]uint64) {
+	if len(bins) <= defaultMaxBins {
+		return
+	}
+	keys := sortedKeys(bins, false)
+	excess := len(keys) - defaultMaxBins
+	target := keys[excess]
+	for _, k := range keys[:excess] {
+		bins[target] += bins[k]
+		delete(bins, k)
+	}
+}
+
+func sortedKeys(m map[int]uint64, desc bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.IntSlice(keys)))
+	} else {
+		sort.Ints(keys)
+	}
+	return keys
+}
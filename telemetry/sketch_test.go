@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+)
+
+// exactQuantile matches the rank definition documented on Sketch.
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[int(math.Floor(q*float64(len(sorted)-1)))]
+}
+
+func TestSketchRelativeError(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	dists := map[string]func() float64{
+		"lognormal":  func() float64 { return math.Exp(r.NormFloat64() * 2) },
+		"uniform":    func() float64 { return r.Float64() * 1000 },
+		"mixed-sign": func() float64 { return r.NormFloat64() * 50 },
+	}
+	for name, gen := range dists {
+		for _, alpha := range []float64{0.01, 0.05} {
+			s, err := NewSketch(alpha)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vals := make([]float64, 20000)
+			for i := range vals {
+				vals[i] = gen()
+				s.Add(vals[i])
+			}
+			sort.Float64s(vals)
+			for _, q := range []float64{0, 0.1, 0.5, 0.9, 0.95, 0.99, 0.999, 1} {
+				want := exactQuantile(vals, q)
+				got := s.Quantile(q)
+				if math.Abs(got-want) > alpha*math.Abs(want)*(1+1e-9) {
+					t.Errorf("%s α=%v q=%v: got %v, want %v (rel err %.4f)", name, alpha, q, got, want, math.Abs(got-want)/math.Abs(want))
+				}
+			}
+		}
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	a, _ := NewSketch(0.01)
+	b, _ := NewSketch(0.01)
+	whole, _ := NewSketch(0.01)
+	for i := 1; i <= 1000; i++ {
+		v := float64(i)
+		whole.Add(v)
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		if a.Quantile(q) != whole.Quantile(q) {
+			t.Errorf("q=%v: merged %v, whole %v", q, a.Quantile(q), whole.Quantile(q))
+		}
+	}
+	if a.Count() != 1000 || a.Min() != 1 || a.Max() != 1000 || a.Sum() != 500500 {
+		t.Errorf("count/min/max/sum = %d/%v/%v/%v", a.Count(), a.Min(), a.Max(), a.Sum())
+	}
+	other, _ := NewSketch(0.02)
+	if err := a.Merge(other); err == nil {
+		t.Error("merged sketches with different accuracy")
+	}
+}
+
+func TestSketchEdgeCases(t *testing.T) {
+	if _, err := NewSketch(0); err == nil {
+		t.Error("accepted zero accuracy")
+	}
+	s, _ := NewSketch(0.01)
+	if !math.IsNaN(s.Quantile(0.5)) {
+		t.Error("empty sketch quantile is not NaN")
+	}
+	s.Add(0)
+	s.Add(math.NaN())
+	s.Add(42)
+	if s.Count() != 2 || s.Quantile(0) != 0 || s.Quantile(1) != 42 || !math.IsNaN(s.Quantile(1.5)) {
+		t.Errorf("count=%d q0=%v q1=%v", s.Count(), s.Quantile(0), s.Quantile(1))
+	}
+}
+
+func TestBucketHistogram(t *testing.T) {
+	if _, err := NewBucketHistogram([]float64{1, 1}); err == nil {
+		t.Error("accepted non-increasing bounds")
+	}
+	if _, err := NewBucketHistogram([]float64{1, math.Inf(1)}); err == nil {
+		t.Error("accepted infinite bound")
+	}
+	h, err := NewBucketHistogram(LinearBuckets(10, 10, 10)) // 10, 20, ..., 100
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 100; i++ {
+		h.Observe(float64(i))
+	}
+	b := h.Buckets()
+	if len(b) != 11 || b[0].Count != 10 || b[9].Count != 10 || b[10].Count != 0 || !math.IsInf(b[10].UpperBound, 1) {
+		t.Errorf("buckets = %+v", b)
+	}
+	if got := h.Quantile(0.5); math.Abs(got-50) > 10 {
+		t.Errorf("p50 = %v", got)
+	}
+	if got := h.Quantile(0.99); math.Abs(got-99) > 10 {
+		t.Errorf("p99 = %v", got)
+	}
+
+	h2, _ := NewBucketHistogram(LinearBuckets(10, 10, 10))
+	h2.Observe(500)
+	if err := h.Merge(h2); err != nil {
+		t.Fatal(err)
+	}
+	if h.Count() != 101 || h.Max() != 500 || h.Buckets()[10].Count != 1 {
+		t.Errorf("after merge: count=%d max=%v", h.Count(), h.Max())
+	}
+	h3, _ := NewBucketHistogram(ExponentialBuckets(1, 2, 4))
+	if err := h.Merge(h3); err == nil {
+		t.Error("merged histograms with different bounds")
+	}
+}
+
+func TestAggregatorHistogramOptions(t *testing.T) {
+	var got []Aggregate
+	a := NewAggregator(func(_ context.Context, aggs []Aggregate) error {
+		got = aggs
+		return nil
+	}, WithFlushInterval(0), WithHistogramBuckets("tool_seconds", []float64{1, 2}),
+		WithHistogramBuckets("bad", []float64{2, 1}))
+	ctx := context.Background()
+	if err := a.Record(ctx, Metric{Name: "bad", Type: Histogram, Value: 1}); err == nil {
+		t.Error("expected error for invalid bounds")
+	}
+	for i := 1; i <= 100; i++ {
+		_ = a.Record(ctx, Metric{Name: "tool_seconds", Type: Histogram, Value: float64(i) / 50})
+	}
+	_ = a.Flush(ctx)
+	if len(got) != 1 || len(got[0].Buckets) != 3 || got[0].Sketch == nil {
+		t.Fatalf("aggregates = %+v", got)
+	}
+	if p90 := got[0].Sketch.Quantile(0.9); math.Abs(p90-1.8) > 0.01*1.8 {
+		t.Errorf("p90 = %v", p90)
+	}
+}
@@ -0,0 +1,62 @@
+package packtrack
+
+import "fmt"
+
+// Validate checks required fields and enum values. It returns a
+// *ValidationError listing every problem, or nil if the event is valid.
+func (e Event) Validate() error {
+	var fields []FieldError
+	add := func(field, msg string) { fields = append(fields, FieldError{Field: field, Message: msg}) }
+
+	if e.Timestamp.IsZero() {
+		add("timestamp", "required")
+	}
+	if e.Source.System == "" {
+		add("source.system", "required")
+	}
+	if e.Workflow.ID == "" {
+		add("workflow.id", "required")
+	}
+	if e.Actor.Type == "" {
+		add("actor.type", "required")
+	}
+	if e.Actor.ID == "" {
+		add("actor.id", "required")
+	}
+	switch {
+	case e.Severity.Valid():
+	case e.Severity == 0:
+		add("severity", "required")
+	case e.Severity == SeverityUnknown:
+		add("severity", "unknown value, want debug|info|warn|error")
+	default:
+		add("severity", fmt.Sprintf("invalid value %d, want debug|info|warn|error", int(e.Severity)))
+	}
+	switch e.Status {
+	case StatusRunning, StatusSuccess, StatusError:
+	case "":
+		add("status", "required")
+	default:
+		add("status", fmt.Sprintf("invalid value %q, want running|success|error", e.Status))
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// ValidateBatch validates every event and returns a *BatchValidationError
+// naming the offending indexes, or nil if all events are valid.
+func ValidateBatch(events []Event) error {
+	var be *BatchValidationError
+	for i, e := range events {
+		if err := e.Validate(); err != nil {
+			be = be.add(i, err.(*ValidationError))
+		}
+	}
+	if be == nil {
+		return nil
+	}
+	return be
+}
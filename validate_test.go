@@ -0,0 +1,87 @@
+package packtrack
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	if err := newTestEvent().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_FieldErrors(t *testing.T) {
+	e := Event{Severity: Severity(42), Status: "done"}
+	err := e.Validate()
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	for _, f := range []string{"timestamp", "source.system", "workflow.id", "actor.type", "actor.id", "severity", "status"} {
+		if !ve.Has(f) {
+			t.Fatalf("expected field error for %s in %v", f, ve)
+		}
+	}
+	if len(ve.Fields) != 7 {
+		t.Fatalf("expected 7 field errors, got %d", len(ve.Fields))
+	}
+}
+
+func TestValidateBatch_Indexes(t *testing.T) {
+	bad := newTestEvent()
+	bad.Workflow.ID = ""
+	err := ValidateBatch([]Event{newTestEvent(), bad, newTestEvent(), bad})
+	var be *BatchValidationError
+	if !errors.As(err, &be) {
+		t.Fatalf("expected BatchValidationError, got %v", err)
+	}
+	if len(be.Indexes) != 2 || be.Indexes[0] != 1 || be.Indexes[1] != 3 {
+		t.Fatalf("unexpected indexes %v", be.Indexes)
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) || !ve.Has("workflow.id") {
+		t.Fatalf("expected unwrap to ValidationError")
+	}
+}
+
+func TestStrictValidation_RejectsBeforeSend(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL), WithAPIKey("k"), WithStrictValidation())
+	bad := newTestEvent()
+	bad.Severity = Severity(9)
+	if _, err := c.IngestEvent(context.Background(), bad); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if _, err := c.IngestBatch(context.Background(), []Event{newTestEvent(), bad}); err == nil {
+		t.Fatalf("expected batch validation error")
+	}
+	if _, err := c.IngestEvent(context.Background(), newTestEvent()); err != nil {
+		t.Fatalf("valid event rejected: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected only the valid event to be sent, got %d calls", calls)
+	}
+}
+
+func TestStrictValidation_AsyncEnqueue(t *testing.T) {
+	c, _ := NewClient(WithBaseURL("http://example"), WithAPIKey("k"), WithStrictValidation())
+	ac, _ := NewAsyncClient(c, WithFlushInterval(time.Hour))
+	defer ac.Close(context.Background())
+	bad := newTestEvent()
+	bad.Actor = Actor{}
+	var ve *ValidationError
+	if err := ac.Enqueue(bad); !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError at enqueue, got %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+package packtrack
+
+// Version is the SDK version, reported in the default User-Agent and by
+// HostEnricher.
+const Version = "0.1.0"